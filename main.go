@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	certmanager "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 
@@ -37,7 +38,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	awspcacertmanageriov1beta1 "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	awspca "github.com/cert-manager/aws-privateca-issuer/pkg/aws"
 	"github.com/cert-manager/aws-privateca-issuer/pkg/controllers"
+	"github.com/cert-manager/aws-privateca-issuer/pkg/webhooks"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,6 +62,15 @@ func main() {
 	var probeAddr string
 	var disableApprovedCheck bool
 	var disableClientSideRateLimiting bool
+	var issuerResyncInterval time.Duration
+	var caExpiryWarningThreshold time.Duration
+	var clusterResourceNamespace string
+	var enableWebhooks bool
+	var issuanceTimeout time.Duration
+	var rateLimits awspca.RateLimits
+	var issuerMaxConcurrentReconciles int
+	var clusterIssuerMaxConcurrentReconciles int
+	var certificateRequestMaxConcurrentReconciles int
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -70,6 +82,31 @@ func main() {
 	flag.BoolVar(&disableClientSideRateLimiting, "disable-client-side-rate-limiting", false,
 		"Disables Kubernetes client-side rate limiting (only use if API Priority & Fairness is enabled on the cluster).")
 
+	flag.DurationVar(&issuerResyncInterval, "issuer-resync-interval", time.Hour,
+		"How often issuers and their CAs are re-verified. Set to 0 to only verify issuers when they change.")
+	flag.DurationVar(&caExpiryWarningThreshold, "ca-expiry-warning-threshold", 30*24*time.Hour,
+		"How long before the CA certificate expires issuers are marked CAExpiringSoon. Set to 0 to disable.")
+	flag.StringVar(&clusterResourceNamespace, "cluster-resource-namespace", "",
+		"The only namespace AWSPCAClusterIssuers and AWSPCAClusterCredentials may read Secrets from. Leave empty to allow any namespace.")
+	flag.DurationVar(&issuanceTimeout, "issuance-timeout", time.Hour,
+		"How long to wait for PCA to issue a certificate before failing its CertificateRequest. Set to 0 to wait forever.")
+	flag.Float64Var(&rateLimits.Account.RequestsPerSecond, "pca-rate-limit", awspca.DefaultRateLimits.Account.RequestsPerSecond,
+		"How many calls per second are made to each of IssueCertificate, GetCertificate and RevokeCertificate in an account and region. Set to 0 to disable.")
+	flag.IntVar(&rateLimits.Account.Burst, "pca-rate-limit-burst", awspca.DefaultRateLimits.Account.Burst,
+		"How many calls can be made at once to each of IssueCertificate, GetCertificate and RevokeCertificate in an account and region.")
+	flag.Float64Var(&rateLimits.CA.RequestsPerSecond, "pca-ca-rate-limit", awspca.DefaultRateLimits.CA.RequestsPerSecond,
+		"How many calls per second are made to each of IssueCertificate, GetCertificate and RevokeCertificate for a CA, unless its issuer sets rateLimit. Set to 0 to disable.")
+	flag.IntVar(&rateLimits.CA.Burst, "pca-ca-rate-limit-burst", awspca.DefaultRateLimits.CA.Burst,
+		"How many calls can be made at once to each of IssueCertificate, GetCertificate and RevokeCertificate for a CA. Defaults to the rate.")
+	flag.IntVar(&issuerMaxConcurrentReconciles, "awspcaissuer-max-concurrent-reconciles", 1,
+		"How many AWSPCAIssuers are reconciled at once.")
+	flag.IntVar(&clusterIssuerMaxConcurrentReconciles, "awspcaclusterissuer-max-concurrent-reconciles", 1,
+		"How many AWSPCAClusterIssuers are reconciled at once.")
+	flag.IntVar(&certificateRequestMaxConcurrentReconciles, "certificaterequest-max-concurrent-reconciles", 1,
+		"How many CertificateRequests are reconciled at once.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serves the defaulting and validating admission webhooks of the issuers. Requires the webhook configuration and serving certificate, which only the kustomize configuration installs.")
+
 	opts := zap.Options{
 		Development: false,
 	}
@@ -78,6 +115,9 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	awspca.SetRateLimits(rateLimits)
+	awspca.SetClusterResourceNamespace(clusterResourceNamespace)
+
 	config := ctrl.GetConfigOrDie()
 	if disableClientSideRateLimiting {
 		// A negative QPS and Burst indicates that the client should not have a rate limiter.
@@ -88,6 +128,8 @@ func main() {
 	}
 	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme: scheme,
+		Cache:  controllers.CacheOptions(),
+		Client: controllers.ClientOptions(),
 		Metrics: metricsserver.Options{
 			BindAddress: metricsAddr,
 		},
@@ -109,12 +151,18 @@ func main() {
 		Scheme:            mgr.GetScheme(),
 		Recorder:          mgr.GetEventRecorderFor("awspcaissuer-controller"),
 		GetCallerIdentity: true,
+
+		Clock:                    clock.RealClock{},
+		ResyncInterval:           issuerResyncInterval,
+		CAExpiryWarningThreshold: caExpiryWarningThreshold,
 	}
 	if err = (&controllers.AWSPCAIssuerReconciler{
 		Client:            mgr.GetClient(),
 		Log:               ctrl.Log.WithName("controllers").WithName("AWSPCAIssuer"),
 		Scheme:            mgr.GetScheme(),
 		GenericController: genericIssuerController,
+
+		MaxConcurrentReconciles: issuerMaxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AWSPCAIssuer")
 		os.Exit(1)
@@ -124,6 +172,8 @@ func main() {
 		Log:               ctrl.Log.WithName("controllers").WithName("AWSPCAClusterIssuer"),
 		Scheme:            mgr.GetScheme(),
 		GenericController: genericIssuerController,
+
+		MaxConcurrentReconciles: clusterIssuerMaxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AWSPCAClusterIssuer")
 		os.Exit(1)
@@ -134,12 +184,20 @@ func main() {
 		Scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("awspcaissuer-controller"),
 
-		Clock:                  clock.RealClock{},
-		CheckApprovedCondition: !disableApprovedCheck,
+		Clock:                   clock.RealClock{},
+		CheckApprovedCondition:  !disableApprovedCheck,
+		IssuanceTimeout:         issuanceTimeout,
+		MaxConcurrentReconciles: certificateRequestMaxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "CertificateRequest")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = webhooks.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhooks")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
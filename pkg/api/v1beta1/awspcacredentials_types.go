@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CredentialsKind is the kind of namespaced credentials
+	CredentialsKind = "AWSPCACredentials"
+	// ClusterCredentialsKind is the kind of cluster-scoped credentials
+	ClusterCredentialsKind = "AWSPCAClusterCredentials"
+)
+
+// AWSPCACredentialsSpec defines how issuers obtain AWS credentials. The fields
+// have the same meaning as on AWSPCAIssuerSpec.
+type AWSPCACredentialsSpec struct {
+	// Specifies the region used to obtain credentials, and the region of
+	// issuers that do not set one
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Needs to be specified if you want to authorize with AWS using an access and secret key
+	// +optional
+	SecretRef AWSCredentialsSecretReference `json:"secretRef,omitempty"`
+	// Obtains credentials from IAM Roles Anywhere with a certificate
+	// +optional
+	RolesAnywhere *RolesAnywhere `json:"rolesAnywhere,omitempty"`
+	// Specifies the ARN of role to assume
+	// +optional
+	Role string `json:"role,omitempty"`
+	// Specifies how the role is assumed. Requires role to be set.
+	// +optional
+	AssumeRoleOptions *AssumeRoleOptions `json:"assumeRoleOptions,omitempty"`
+	// Specifies roles that are assumed in order. Cannot be combined with role.
+	// +optional
+	RoleChain []RoleChainHop `json:"roleChain,omitempty"`
+	// Overrides the STS and IAM Roles Anywhere endpoints used to obtain
+	// credentials. The acmPCA endpoint is taken from the issuer.
+	// +optional
+	Endpoints *AWSEndpoints `json:"endpoints,omitempty"`
+}
+
+// CredentialsReference refers to AWSPCACredentials or AWSPCAClusterCredentials
+type CredentialsReference struct {
+	// Specifies the name of the credentials
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Specifies the kind of the credentials. AWSPCAIssuers can only refer to
+	// AWSPCACredentials in their namespace, and AWSPCAClusterIssuers to
+	// AWSPCAClusterCredentials.
+	// +kubebuilder:validation:Enum=AWSPCACredentials;AWSPCAClusterCredentials
+	// +optional
+	Kind string `json:"kind,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AWSPCACredentials is the Schema for the awspcacredentials API
+// +kubebuilder:resource:path=awspcacredentials
+type AWSPCACredentials struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AWSPCACredentialsSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AWSPCACredentialsList contains a list of AWSPCACredentials
+type AWSPCACredentialsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSPCACredentials `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+
+// AWSPCAClusterCredentials is the Schema for the awspcaclustercredentials API
+// +kubebuilder:resource:path=awspcaclustercredentials,scope=Cluster
+type AWSPCAClusterCredentials struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AWSPCACredentialsSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AWSPCAClusterCredentialsList contains a list of AWSPCAClusterCredentials
+type AWSPCAClusterCredentialsList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AWSPCAClusterCredentials `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&AWSPCACredentials{}, &AWSPCACredentialsList{})
+	SchemeBuilder.Register(&AWSPCAClusterCredentials{}, &AWSPCAClusterCredentialsList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -31,15 +32,363 @@ type AWSPCAIssuerSpec struct {
 
 	// Specifies the ARN of the PCA resource
 	Arn string `json:"arn,omitempty"`
-	// Should contain the AWS region if it cannot be inferred
+	// Specifies the AWS region. Defaults to the region in the ARN of the PCA
+	// resource, and must match it when set.
 	// +optional
 	Region string `json:"region,omitempty"`
+	// Overrides the endpoints the issuer calls AWS services at
+	// +optional
+	Endpoints *AWSEndpoints `json:"endpoints,omitempty"`
+	// Specifies that FIPS endpoints are used
+	// +optional
+	UseFIPS bool `json:"useFIPS,omitempty"`
+	// Specifies that dual-stack (IPv4 and IPv6) endpoints are used
+	// +optional
+	UseDualStack bool `json:"useDualStack,omitempty"`
+	// Refers to AWSPCACredentials or AWSPCAClusterCredentials shared with other
+	// issuers, instead of configuring credentials on the issuer
+	// +optional
+	CredentialsRef *CredentialsReference `json:"credentialsRef,omitempty"`
 	// Needs to be specified if you want to authorize with AWS using an access and secret key
 	// +optional
 	SecretRef AWSCredentialsSecretReference `json:"secretRef,omitempty"`
+	// Obtains credentials from IAM Roles Anywhere with a certificate, instead
+	// of from secretRef or the environment
+	// +optional
+	RolesAnywhere *RolesAnywhere `json:"rolesAnywhere,omitempty"`
 	// Specifies the ARN of role to assume when issuing certificates.
 	// +optional
 	Role string `json:"role,omitempty"`
+	// Specifies how the role is assumed. Requires role to be set.
+	// +optional
+	AssumeRoleOptions *AssumeRoleOptions `json:"assumeRoleOptions,omitempty"`
+	// Specifies roles that are assumed in order, each with the credentials of
+	// the previous one, when the CA cannot be reached by assuming a single role.
+	// Cannot be combined with role.
+	// +optional
+	RoleChain []RoleChainHop `json:"roleChain,omitempty"`
+	// Specifies the PCA template used for every certificate issued by this issuer,
+	// instead of the template inferred from the CertificateRequest usages.
+	// Accepts either a full template ARN or a template name and version,
+	// e.g. SubordinateCACertificate_PathLen1/V1
+	// +optional
+	TemplateArn string `json:"templateArn,omitempty"`
+	// Specifies the PCA templates a CertificateRequest may select with the
+	// aws-privateca-issuer/template-arn annotation. Accepts the same formats as templateArn.
+	// CertificateRequests cannot select a template unless it is listed here.
+	// +optional
+	AllowedTemplateArns []string `json:"allowedTemplateArns,omitempty"`
+	// Specifies extensions and subject values passed to PCA alongside the CSR.
+	// When set, the template inferred from the CertificateRequest usages is
+	// replaced by its APIPassthrough variant.
+	// +optional
+	ApiPassthrough *ApiPassthrough `json:"apiPassthrough,omitempty"`
+	// Specifies when certificates issued by this issuer are revoked.
+	// Never (the default) never revokes certificates. OnCertificateRequestDelete
+	// revokes a certificate when its CertificateRequest is deleted. OnAnnotation
+	// revokes a certificate when its CertificateRequest is annotated with
+	// aws-privateca-issuer/revoke: "true".
+	// +optional
+	RevocationPolicy RevocationPolicy `json:"revocationPolicy,omitempty"`
+	// Specifies the RFC 5280 reason recorded when revoking certificates. Defaults to UNSPECIFIED.
+	// +kubebuilder:validation:Enum=UNSPECIFIED;KEY_COMPROMISE;CERTIFICATE_AUTHORITY_COMPROMISE;AFFILIATION_CHANGED;SUPERSEDED;CESSATION_OF_OPERATION;PRIVILEGE_WITHDRAWN;A_A_COMPROMISE
+	// +optional
+	RevocationReason string `json:"revocationReason,omitempty"`
+	// Specifies how often the issuer and its CA are re-verified, overriding
+	// the controller's --issuer-resync-interval. Set to 0s to disable
+	// periodic re-verification of this issuer.
+	// +optional
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+	// Specifies the validity of certificates whose CertificateRequest does not
+	// request a duration. Defaults to 720h (30 days), which is also used when
+	// it is unset.
+	// +kubebuilder:default="720h"
+	// +optional
+	DefaultDuration *metav1.Duration `json:"defaultDuration,omitempty"`
+	// Specifies the longest validity of issued certificates. Longer durations,
+	// including defaultDuration, are shortened to it. The CertificateRequest
+	// records a shortened duration in its Ready condition, an event and the
+	// aws-privateca-issuer/validity-shortened annotation.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+	// Specifies how far in the past the validity of issued certificates starts,
+	// to tolerate clocks that are behind. By default certificates are valid
+	// from the time they are issued.
+	// +optional
+	NotBeforeBackdate *metav1.Duration `json:"notBeforeBackdate,omitempty"`
+	// Specifies the algorithm certificates are signed with, instead of the CA's
+	// default signing algorithm. It must match the family of the CA's key, e.g.
+	// SHA384WITHRSA for an RSA key. CertificateRequests may select another
+	// algorithm with the aws-privateca-issuer/signing-algorithm annotation.
+	// +kubebuilder:validation:Enum=SHA256WITHECDSA;SHA384WITHECDSA;SHA512WITHECDSA;SHA256WITHRSA;SHA384WITHRSA;SHA512WITHRSA;SM3WITHSM2
+	// +optional
+	SigningAlgorithm string `json:"signingAlgorithm,omitempty"`
+	// Specifies what happens when a certificate would outlive the CA's certificate.
+	// Fail (the default) fails the CertificateRequest. Clamp issues the
+	// certificate with a validity that ends when the CA's certificate expires.
+	// +optional
+	CAExpiryPolicy CAExpiryPolicy `json:"caExpiryPolicy,omitempty"`
+	// Specifies the CertificateRequests the issuer signs. Requests that
+	// violate the policy are failed without calling PCA.
+	// +optional
+	IssuancePolicy *IssuancePolicy `json:"issuancePolicy,omitempty"`
+	// Limits the rate at which certificates are issued, retrieved and revoked
+	// by the CA, overriding the per CA limit of the controller. Issuers sharing a CA
+	// share its limit.
+	// +optional
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit is a token bucket limiting the calls made to PCA
+type RateLimit struct {
+	// The number of calls per second to each of IssueCertificate,
+	// GetCertificate and RevokeCertificate.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+	// The number of calls that can be made at once after the limit has not
+	// been reached for a while. Defaults to requestsPerSecond.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// IssuancePolicy defines the CertificateRequests an issuer signs. Unset fields
+// allow anything, except for isCA, which must be allowed explicitly.
+type IssuancePolicy struct {
+	// Specifies the key algorithms, and their minimum sizes, of the CSRs that
+	// are signed. A CSR must match one of them.
+	// +optional
+	AllowedKeyAlgorithms []KeyAlgorithmPolicy `json:"allowedKeyAlgorithms,omitempty"`
+	// Specifies regular expressions that DNS names must match in full, unless
+	// they end in one of allowedDomainSuffixes. Applies to the common name too.
+	// +optional
+	AllowedDNSNames []string `json:"allowedDNSNames,omitempty"`
+	// Specifies domains whose subdomains are allowed as DNS names, e.g.
+	// example.com allows foo.example.com but neither example.com nor fooexample.com
+	// +optional
+	AllowedDomainSuffixes []string `json:"allowedDomainSuffixes,omitempty"`
+	// Specifies the CIDR ranges that IP addresses must be in
+	// +optional
+	AllowedIPRanges []string `json:"allowedIPRanges,omitempty"`
+	// Specifies regular expressions that URIs must match in full
+	// +optional
+	AllowedURIs []string `json:"allowedURIs,omitempty"`
+	// Specifies the usages CertificateRequests may request, either in their
+	// usages or in the key usage and extended key usage extensions of their
+	// CSR. CertificateRequests without usages request digital signature and
+	// key encipherment.
+	// +optional
+	AllowedUsages []cmapi.KeyUsage `json:"allowedUsages,omitempty"`
+	// Specifies whether CertificateRequests for CA certificates are signed
+	// +optional
+	AllowCA bool `json:"allowCA,omitempty"`
+	// Specifies the longest duration of issued certificates, including those
+	// given the issuer's defaultDuration. Unlike maxDuration, requests for
+	// longer durations are failed rather than shortened.
+	// +optional
+	MaxDuration *metav1.Duration `json:"maxDuration,omitempty"`
+}
+
+// KeyAlgorithmPolicy defines a key algorithm allowed by an IssuancePolicy
+type KeyAlgorithmPolicy struct {
+	// +kubebuilder:validation:Enum=RSA;ECDSA;Ed25519
+	Algorithm string `json:"algorithm"`
+	// Specifies the minimum size of the key in bits, e.g. 2048 for RSA or 384 for ECDSA
+	// +optional
+	MinSize int `json:"minSize,omitempty"`
+}
+
+// AWSEndpoints defines the URLs of the AWS service endpoints used by an issuer,
+// e.g. of VPC interface endpoints
+type AWSEndpoints struct {
+	// Specifies the URL of the ACM PCA endpoint
+	// +optional
+	ACMPCA string `json:"acmPCA,omitempty"`
+	// Specifies the URL of the STS endpoint, which is used to assume roles
+	// +optional
+	STS string `json:"sts,omitempty"`
+	// Specifies the URL of the IAM Roles Anywhere endpoint
+	// +optional
+	RolesAnywhere string `json:"rolesAnywhere,omitempty"`
+}
+
+// RolesAnywhere defines how credentials are obtained from IAM Roles Anywhere
+type RolesAnywhere struct {
+	// Specifies the kubernetes.io/tls Secret holding the certificate, and any
+	// intermediate certificates, in tls.crt and its private key in tls.key.
+	// The Secret is read again whenever the credentials are refreshed, so it
+	// can be managed by a cert-manager Certificate.
+	CertificateSecretRef v1.SecretReference `json:"certificateSecretRef"`
+	// Specifies the ARN of the trust anchor the certificate chains to
+	// +kubebuilder:validation:MinLength=1
+	TrustAnchorArn string `json:"trustAnchorArn"`
+	// Specifies the ARN of the Roles Anywhere profile
+	// +kubebuilder:validation:MinLength=1
+	ProfileArn string `json:"profileArn"`
+	// Specifies the ARN of the role to obtain credentials for
+	// +kubebuilder:validation:MinLength=1
+	RoleArn string `json:"roleArn"`
+	// Specifies how long the credentials last, between 15m and 12h. Defaults to 1h.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+}
+
+// AssumeRoleOptions defines the parameters passed to sts:AssumeRole
+type AssumeRoleOptions struct {
+	// Specifies the external ID required by the role's trust policy
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+	// Specifies the role session name as a Go template, which is given the
+	// Kind, Name and Namespace of the issuer, e.g. "pca-{{ .Namespace }}-{{ .Name }}".
+	// Characters not allowed in a session name are replaced with '-' and the
+	// result is truncated to 64 characters.
+	// +optional
+	SessionName string `json:"sessionName,omitempty"`
+	// Specifies how long the role session lasts, between 15m and 12h
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// Specifies the session tags passed to the role session
+	// +optional
+	Tags []SessionTag `json:"tags,omitempty"`
+	// Specifies the keys of session tags that persist when the role session
+	// is used to assume another role
+	// +optional
+	TransitiveTagKeys []string `json:"transitiveTagKeys,omitempty"`
+	// Specifies the source identity recorded for the role session
+	// +optional
+	SourceIdentity string `json:"sourceIdentity,omitempty"`
+}
+
+// RoleChainHop is a role assumed as part of a roleChain
+type RoleChainHop struct {
+	// Specifies the ARN of the role to assume
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+
+	AssumeRoleOptions `json:",inline"`
+}
+
+// SessionTag is a tag attached to a role session
+type SessionTag struct {
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=128
+	Key string `json:"key"`
+	// +kubebuilder:validation:MaxLength=256
+	Value string `json:"value"`
+}
+
+// RevocationPolicy defines when issued certificates are revoked
+// +kubebuilder:validation:Enum=Never;OnCertificateRequestDelete;OnAnnotation
+type RevocationPolicy string
+
+const (
+	// RevocationPolicyNever never revokes issued certificates
+	RevocationPolicyNever RevocationPolicy = "Never"
+	// RevocationPolicyOnCertificateRequestDelete revokes certificates when their CertificateRequest is deleted
+	RevocationPolicyOnCertificateRequestDelete RevocationPolicy = "OnCertificateRequestDelete"
+	// RevocationPolicyOnAnnotation revokes certificates when their CertificateRequest is annotated
+	RevocationPolicyOnAnnotation RevocationPolicy = "OnAnnotation"
+)
+
+// CAExpiryPolicy defines how certificates that would outlive the CA are handled
+// +kubebuilder:validation:Enum=Fail;Clamp
+type CAExpiryPolicy string
+
+const (
+	// CAExpiryPolicyFail fails CertificateRequests for certificates that would outlive the CA
+	CAExpiryPolicyFail CAExpiryPolicy = "Fail"
+	// CAExpiryPolicyClamp shortens certificates that would outlive the CA to the CA's expiry
+	CAExpiryPolicyClamp CAExpiryPolicy = "Clamp"
+)
+
+// ApiPassthrough defines values that are added to every certificate issued by the issuer
+type ApiPassthrough struct {
+	// Specifies X.509 extensions added to issued certificates
+	// +optional
+	Extensions *ApiPassthroughExtensions `json:"extensions,omitempty"`
+	// Specifies subject values that override the subject of the CSR
+	// +optional
+	Subject *ApiPassthroughSubject `json:"subject,omitempty"`
+}
+
+// ApiPassthroughExtensions defines the X.509 extensions added to issued certificates
+type ApiPassthroughExtensions struct {
+	// Specifies the certificate policies of issued certificates
+	// +optional
+	CertificatePolicies []CertificatePolicy `json:"certificatePolicies,omitempty"`
+	// Specifies extended key usage OIDs added to issued certificates
+	// +kubebuilder:validation:items:Pattern=`^([0-2])((\.0)|(\.[1-9][0-9]*))*$`
+	// +optional
+	ExtendedKeyUsages []string `json:"extendedKeyUsages,omitempty"`
+	// Specifies custom extensions added to issued certificates
+	// +optional
+	CustomExtensions []CustomExtension `json:"customExtensions,omitempty"`
+}
+
+// CertificatePolicy defines a certificate policy and its optional CPS qualifier
+type CertificatePolicy struct {
+	// Specifies the OID of the policy
+	// +kubebuilder:validation:Pattern=`^([0-2])((\.0)|(\.[1-9][0-9]*))*$`
+	PolicyID string `json:"policyID"`
+	// Specifies the URI of the certification practice statement of the policy
+	// +optional
+	CPSUri string `json:"cpsUri,omitempty"`
+}
+
+// CustomExtension defines an arbitrary X.509 extension
+type CustomExtension struct {
+	// Specifies the OID of the extension
+	// +kubebuilder:validation:Pattern=`^([0-2])((\.0)|(\.[1-9][0-9]*))*$`
+	ObjectIdentifier string `json:"objectIdentifier"`
+	// Specifies the base64 encoded DER value of the extension
+	Value string `json:"value"`
+	// Specifies whether the extension is marked as critical
+	// +optional
+	Critical bool `json:"critical,omitempty"`
+}
+
+// ApiPassthroughSubject defines subject values that override the subject of the CSR
+type ApiPassthroughSubject struct {
+	// +optional
+	CommonName string `json:"commonName,omitempty"`
+	// +optional
+	Country string `json:"country,omitempty"`
+	// +optional
+	State string `json:"state,omitempty"`
+	// +optional
+	Locality string `json:"locality,omitempty"`
+	// +optional
+	Organization string `json:"organization,omitempty"`
+	// +optional
+	OrganizationalUnit string `json:"organizationalUnit,omitempty"`
+	// +optional
+	SerialNumber string `json:"serialNumber,omitempty"`
+	// +optional
+	Title string `json:"title,omitempty"`
+	// +optional
+	GivenName string `json:"givenName,omitempty"`
+	// +optional
+	Surname string `json:"surname,omitempty"`
+	// +optional
+	Initials string `json:"initials,omitempty"`
+	// +optional
+	Pseudonym string `json:"pseudonym,omitempty"`
+	// +optional
+	GenerationQualifier string `json:"generationQualifier,omitempty"`
+	// +optional
+	DistinguishedNameQualifier string `json:"distinguishedNameQualifier,omitempty"`
+	// Specifies subject attributes by OID. Cannot be combined with the other subject fields.
+	// +optional
+	CustomAttributes []SubjectCustomAttribute `json:"customAttributes,omitempty"`
+}
+
+// SubjectCustomAttribute defines a subject attribute by OID
+type SubjectCustomAttribute struct {
+	// Specifies the OID of the attribute
+	// +kubebuilder:validation:Pattern=`^([0-2])((\.0)|(\.[1-9][0-9]*))*$`
+	ObjectIdentifier string `json:"objectIdentifier"`
+	// Specifies the value of the attribute
+	Value string `json:"value"`
 }
 
 // AWSCredentialsSecretReference defines the secret used by the issuer
@@ -51,6 +400,15 @@ type AWSCredentialsSecretReference struct {
 	// Specifies the secret key where the AWS Secret Access Key exists
 	// +optional
 	SecretAccessKeySelector v1.SecretKeySelector `json:"secretAccessKeySelector,omitempty"`
+	// Specifies the secret key where the AWS Session Token of temporary credentials exists.
+	// Defaults to AWS_SESSION_TOKEN, which may be absent for long-term credentials.
+	// +optional
+	SessionTokenSelector v1.SecretKeySelector `json:"sessionTokenSelector,omitempty"`
+	// Specifies the secret key where the RFC 3339 expiry time of temporary credentials exists.
+	// Defaults to AWS_SESSION_EXPIRATION, which may be absent for long-term credentials.
+	// The secret is re-read shortly before the credentials expire.
+	// +optional
+	ExpirationSelector v1.SecretKeySelector `json:"expirationSelector,omitempty"`
 }
 
 // AWSPCAIssuerStatus defines the observed state of AWSPCAIssuer
@@ -59,13 +417,67 @@ type AWSPCAIssuerStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of the issuer that was last reconciled
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// CAStatus is the status of the certificate authority, e.g. ACTIVE or DISABLED
+	// +optional
+	CAStatus string `json:"caStatus,omitempty"`
+
+	// CAType is the type of the certificate authority, ROOT or SUBORDINATE
+	// +optional
+	CAType string `json:"caType,omitempty"`
+
+	// KeyAlgorithm is the algorithm of the certificate authority's private key
+	// +optional
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+
+	// SigningAlgorithm is the algorithm the certificate authority signs certificates with
+	// +optional
+	SigningAlgorithm string `json:"signingAlgorithm,omitempty"`
+
+	// UsageMode is the usage mode of the certificate authority,
+	// GENERAL_PURPOSE or SHORT_LIVED_CERTIFICATE
+	// +optional
+	UsageMode string `json:"usageMode,omitempty"`
+
+	// NotBefore is the time the certificate authority's certificate becomes valid
+	// +optional
+	NotBefore *metav1.Time `json:"notBefore,omitempty"`
+
+	// NotAfter is the time the certificate authority's certificate expires
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// Serial is the serial number of the certificate authority's certificate
+	// +optional
+	Serial string `json:"serial,omitempty"`
+
+	// AccountID is the AWS account of the identity the issuer authenticates as,
+	// as resolved by STS
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
 }
 
 // ConditionTypeReady is the default condition type for the CRs
 const ConditionTypeReady = "Ready"
 
+// ConditionTypeCAExpiringSoon is set to True once the CA certificate is close to expiring
+const ConditionTypeCAExpiringSoon = "CAExpiringSoon"
+
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="CA Status",type="string",JSONPath=".status.caStatus"
+// +kubebuilder:printcolumn:name="CA Type",type="string",JSONPath=".status.caType"
+// +kubebuilder:printcolumn:name="Usage Mode",type="string",JSONPath=".status.usageMode",priority=1
+// +kubebuilder:printcolumn:name="Key Algorithm",type="string",JSONPath=".status.keyAlgorithm",priority=1
+// +kubebuilder:printcolumn:name="Account",type="string",JSONPath=".status.accountID",priority=1
+// +kubebuilder:printcolumn:name="CA Expires",type="date",JSONPath=".status.notAfter"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // AWSPCAIssuer is the Schema for the awspcaissuers API
 type AWSPCAIssuer struct {
@@ -87,6 +499,15 @@ type AWSPCAIssuerList struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="CA Status",type="string",JSONPath=".status.caStatus"
+// +kubebuilder:printcolumn:name="CA Type",type="string",JSONPath=".status.caType"
+// +kubebuilder:printcolumn:name="Usage Mode",type="string",JSONPath=".status.usageMode",priority=1
+// +kubebuilder:printcolumn:name="Key Algorithm",type="string",JSONPath=".status.keyAlgorithm",priority=1
+// +kubebuilder:printcolumn:name="Account",type="string",JSONPath=".status.accountID",priority=1
+// +kubebuilder:printcolumn:name="CA Expires",type="date",JSONPath=".status.notAfter"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // AWSPCAClusterIssuer is the Schema for the awspcaclusterissuers API
 // +kubebuilder:resource:path=awspcaclusterissuers,scope=Cluster
@@ -21,6 +21,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -31,6 +32,8 @@ func (in *AWSCredentialsSecretReference) DeepCopyInto(out *AWSCredentialsSecretR
 	out.SecretReference = in.SecretReference
 	in.AccessKeyIDSelector.DeepCopyInto(&out.AccessKeyIDSelector)
 	in.SecretAccessKeySelector.DeepCopyInto(&out.SecretAccessKeySelector)
+	in.SessionTokenSelector.DeepCopyInto(&out.SessionTokenSelector)
+	in.ExpirationSelector.DeepCopyInto(&out.ExpirationSelector)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSCredentialsSecretReference.
@@ -43,6 +46,79 @@ func (in *AWSCredentialsSecretReference) DeepCopy() *AWSCredentialsSecretReferen
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSEndpoints) DeepCopyInto(out *AWSEndpoints) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSEndpoints.
+func (in *AWSEndpoints) DeepCopy() *AWSEndpoints {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSEndpoints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSPCAClusterCredentials) DeepCopyInto(out *AWSPCAClusterCredentials) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCAClusterCredentials.
+func (in *AWSPCAClusterCredentials) DeepCopy() *AWSPCAClusterCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSPCAClusterCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSPCAClusterCredentials) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSPCAClusterCredentialsList) DeepCopyInto(out *AWSPCAClusterCredentialsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AWSPCAClusterCredentials, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCAClusterCredentialsList.
+func (in *AWSPCAClusterCredentialsList) DeepCopy() *AWSPCAClusterCredentialsList {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSPCAClusterCredentialsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSPCAClusterCredentialsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSPCAClusterIssuer) DeepCopyInto(out *AWSPCAClusterIssuer) {
 	*out = *in
@@ -102,6 +178,102 @@ func (in *AWSPCAClusterIssuerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSPCACredentials) DeepCopyInto(out *AWSPCACredentials) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCACredentials.
+func (in *AWSPCACredentials) DeepCopy() *AWSPCACredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSPCACredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSPCACredentials) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSPCACredentialsList) DeepCopyInto(out *AWSPCACredentialsList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]AWSPCACredentials, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCACredentialsList.
+func (in *AWSPCACredentialsList) DeepCopy() *AWSPCACredentialsList {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSPCACredentialsList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *AWSPCACredentialsList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSPCACredentialsSpec) DeepCopyInto(out *AWSPCACredentialsSpec) {
+	*out = *in
+	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.RolesAnywhere != nil {
+		in, out := &in.RolesAnywhere, &out.RolesAnywhere
+		*out = new(RolesAnywhere)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AssumeRoleOptions != nil {
+		in, out := &in.AssumeRoleOptions, &out.AssumeRoleOptions
+		*out = new(AssumeRoleOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleChain != nil {
+		in, out := &in.RoleChain, &out.RoleChain
+		*out = make([]RoleChainHop, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(AWSEndpoints)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCACredentialsSpec.
+func (in *AWSPCACredentialsSpec) DeepCopy() *AWSPCACredentialsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSPCACredentialsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSPCAIssuer) DeepCopyInto(out *AWSPCAIssuer) {
 	*out = *in
@@ -164,7 +336,74 @@ func (in *AWSPCAIssuerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSPCAIssuerSpec) DeepCopyInto(out *AWSPCAIssuerSpec) {
 	*out = *in
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = new(AWSEndpoints)
+		**out = **in
+	}
+	if in.CredentialsRef != nil {
+		in, out := &in.CredentialsRef, &out.CredentialsRef
+		*out = new(CredentialsReference)
+		**out = **in
+	}
 	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.RolesAnywhere != nil {
+		in, out := &in.RolesAnywhere, &out.RolesAnywhere
+		*out = new(RolesAnywhere)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AssumeRoleOptions != nil {
+		in, out := &in.AssumeRoleOptions, &out.AssumeRoleOptions
+		*out = new(AssumeRoleOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleChain != nil {
+		in, out := &in.RoleChain, &out.RoleChain
+		*out = make([]RoleChainHop, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AllowedTemplateArns != nil {
+		in, out := &in.AllowedTemplateArns, &out.AllowedTemplateArns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ApiPassthrough != nil {
+		in, out := &in.ApiPassthrough, &out.ApiPassthrough
+		*out = new(ApiPassthrough)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DefaultDuration != nil {
+		in, out := &in.DefaultDuration, &out.DefaultDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NotBeforeBackdate != nil {
+		in, out := &in.NotBeforeBackdate, &out.NotBeforeBackdate
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.IssuancePolicy != nil {
+		in, out := &in.IssuancePolicy, &out.IssuancePolicy
+		*out = new(IssuancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimit)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCAIssuerSpec.
@@ -187,6 +426,14 @@ func (in *AWSPCAIssuerStatus) DeepCopyInto(out *AWSPCAIssuerStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NotBefore != nil {
+		in, out := &in.NotBefore, &out.NotBefore
+		*out = (*in).DeepCopy()
+	}
+	if in.NotAfter != nil {
+		in, out := &in.NotAfter, &out.NotAfter
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSPCAIssuerStatus.
@@ -198,3 +445,300 @@ func (in *AWSPCAIssuerStatus) DeepCopy() *AWSPCAIssuerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiPassthrough) DeepCopyInto(out *ApiPassthrough) {
+	*out = *in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = new(ApiPassthroughExtensions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subject != nil {
+		in, out := &in.Subject, &out.Subject
+		*out = new(ApiPassthroughSubject)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiPassthrough.
+func (in *ApiPassthrough) DeepCopy() *ApiPassthrough {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiPassthrough)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiPassthroughExtensions) DeepCopyInto(out *ApiPassthroughExtensions) {
+	*out = *in
+	if in.CertificatePolicies != nil {
+		in, out := &in.CertificatePolicies, &out.CertificatePolicies
+		*out = make([]CertificatePolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtendedKeyUsages != nil {
+		in, out := &in.ExtendedKeyUsages, &out.ExtendedKeyUsages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomExtensions != nil {
+		in, out := &in.CustomExtensions, &out.CustomExtensions
+		*out = make([]CustomExtension, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiPassthroughExtensions.
+func (in *ApiPassthroughExtensions) DeepCopy() *ApiPassthroughExtensions {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiPassthroughExtensions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApiPassthroughSubject) DeepCopyInto(out *ApiPassthroughSubject) {
+	*out = *in
+	if in.CustomAttributes != nil {
+		in, out := &in.CustomAttributes, &out.CustomAttributes
+		*out = make([]SubjectCustomAttribute, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApiPassthroughSubject.
+func (in *ApiPassthroughSubject) DeepCopy() *ApiPassthroughSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(ApiPassthroughSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssumeRoleOptions) DeepCopyInto(out *AssumeRoleOptions) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]SessionTag, len(*in))
+		copy(*out, *in)
+	}
+	if in.TransitiveTagKeys != nil {
+		in, out := &in.TransitiveTagKeys, &out.TransitiveTagKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssumeRoleOptions.
+func (in *AssumeRoleOptions) DeepCopy() *AssumeRoleOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(AssumeRoleOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificatePolicy) DeepCopyInto(out *CertificatePolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatePolicy.
+func (in *CertificatePolicy) DeepCopy() *CertificatePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificatePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsReference) DeepCopyInto(out *CredentialsReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsReference.
+func (in *CredentialsReference) DeepCopy() *CredentialsReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomExtension) DeepCopyInto(out *CustomExtension) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomExtension.
+func (in *CustomExtension) DeepCopy() *CustomExtension {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomExtension)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuancePolicy) DeepCopyInto(out *IssuancePolicy) {
+	*out = *in
+	if in.AllowedKeyAlgorithms != nil {
+		in, out := &in.AllowedKeyAlgorithms, &out.AllowedKeyAlgorithms
+		*out = make([]KeyAlgorithmPolicy, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedDNSNames != nil {
+		in, out := &in.AllowedDNSNames, &out.AllowedDNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedDomainSuffixes != nil {
+		in, out := &in.AllowedDomainSuffixes, &out.AllowedDomainSuffixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedIPRanges != nil {
+		in, out := &in.AllowedIPRanges, &out.AllowedIPRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedURIs != nil {
+		in, out := &in.AllowedURIs, &out.AllowedURIs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedUsages != nil {
+		in, out := &in.AllowedUsages, &out.AllowedUsages
+		*out = make([]certmanagerv1.KeyUsage, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxDuration != nil {
+		in, out := &in.MaxDuration, &out.MaxDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuancePolicy.
+func (in *IssuancePolicy) DeepCopy() *IssuancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeyAlgorithmPolicy) DeepCopyInto(out *KeyAlgorithmPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeyAlgorithmPolicy.
+func (in *KeyAlgorithmPolicy) DeepCopy() *KeyAlgorithmPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyAlgorithmPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimit) DeepCopyInto(out *RateLimit) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimit.
+func (in *RateLimit) DeepCopy() *RateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleChainHop) DeepCopyInto(out *RoleChainHop) {
+	*out = *in
+	in.AssumeRoleOptions.DeepCopyInto(&out.AssumeRoleOptions)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleChainHop.
+func (in *RoleChainHop) DeepCopy() *RoleChainHop {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleChainHop)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolesAnywhere) DeepCopyInto(out *RolesAnywhere) {
+	*out = *in
+	out.CertificateSecretRef = in.CertificateSecretRef
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolesAnywhere.
+func (in *RolesAnywhere) DeepCopy() *RolesAnywhere {
+	if in == nil {
+		return nil
+	}
+	out := new(RolesAnywhere)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SessionTag) DeepCopyInto(out *SessionTag) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SessionTag.
+func (in *SessionTag) DeepCopy() *SessionTag {
+	if in == nil {
+		return nil
+	}
+	out := new(SessionTag)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubjectCustomAttribute) DeepCopyInto(out *SubjectCustomAttribute) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubjectCustomAttribute.
+func (in *SubjectCustomAttribute) DeepCopy() *SubjectCustomAttribute {
+	if in == nil {
+		return nil
+	}
+	out := new(SubjectCustomAttribute)
+	in.DeepCopyInto(out)
+	return out
+}
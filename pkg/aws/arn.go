@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	awsarn "github.com/aws/aws-sdk-go-v2/aws/arn"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+)
+
+const (
+	acmPCAService                = "acm-pca"
+	certificateAuthorityResource = "certificate-authority/"
+	defaultPartition             = "aws"
+)
+
+// partitionDNSSuffixes are the DNS suffixes of the endpoints in each partition
+var partitionDNSSuffixes = map[string]string{
+	"aws":        "amazonaws.com",
+	"aws-cn":     "amazonaws.com.cn",
+	"aws-us-gov": "amazonaws.com",
+	"aws-iso":    "c2s.ic.gov",
+	"aws-iso-b":  "sc2s.sgov.gov",
+	"aws-iso-e":  "cloud.adc-e.uk",
+	"aws-iso-f":  "csp.hci.ic.gov",
+}
+
+// CertificateAuthorityArn is the parsed ARN of an ACM PCA certificate authority
+type CertificateAuthorityArn struct {
+	Partition string
+	Region    string
+	AccountID string
+	ID        string
+}
+
+// ParseCertificateAuthorityArn parses the ARN of an ACM PCA certificate
+// authority. The account is not checked, so that ARNs with placeholder
+// accounts are accepted.
+func ParseCertificateAuthorityArn(s string) (CertificateAuthorityArn, error) {
+	parsed, err := awsarn.Parse(s)
+	if err != nil {
+		return CertificateAuthorityArn{}, fmt.Errorf("invalid certificate authority ARN %q: %v", s, err)
+	}
+	if parsed.Service != acmPCAService {
+		return CertificateAuthorityArn{}, fmt.Errorf("invalid certificate authority ARN %q: service is %q, not %s", s, parsed.Service, acmPCAService)
+	}
+	id, ok := strings.CutPrefix(parsed.Resource, certificateAuthorityResource)
+	if !ok || id == "" {
+		return CertificateAuthorityArn{}, fmt.Errorf("invalid certificate authority ARN %q: resource is not a certificate authority", s)
+	}
+	if parsed.Region == "" {
+		return CertificateAuthorityArn{}, fmt.Errorf("invalid certificate authority ARN %q: no region", s)
+	}
+
+	return CertificateAuthorityArn{
+		Partition: parsed.Partition,
+		Region:    parsed.Region,
+		AccountID: parsed.AccountID,
+		ID:        id,
+	}, nil
+}
+
+func (a CertificateAuthorityArn) String() string {
+	return awsarn.ARN{
+		Partition: a.Partition,
+		Service:   acmPCAService,
+		Region:    a.Region,
+		AccountID: a.AccountID,
+		Resource:  certificateAuthorityResource + a.ID,
+	}.String()
+}
+
+// TemplateArn returns the ARN of a template, given by its name and version, in
+// the partition of the certificate authority. The aws partition is used when
+// the partition is unknown.
+func (a CertificateAuthorityArn) TemplateArn(template string) string {
+	partition := a.Partition
+	if partition == "" {
+		partition = defaultPartition
+	}
+	return awsarn.ARN{
+		Partition: partition,
+		Service:   acmPCAService,
+		Resource:  "template/" + template,
+	}.String()
+}
+
+// ValidateRegion checks that the region of an issuer, if set, is the region of
+// its certificate authority
+func ValidateRegion(spec *api.AWSPCAIssuerSpec) error {
+	ca, err := ParseCertificateAuthorityArn(spec.Arn)
+	if err != nil {
+		return err
+	}
+	if spec.Region != "" && spec.Region != ca.Region {
+		return fmt.Errorf("region %s does not match the region %s of the certificate authority", spec.Region, ca.Region)
+	}
+	return nil
+}
+
+// issuerRegion returns the region of an issuer, which defaults to the region
+// of its certificate authority
+func issuerRegion(spec *api.AWSPCAIssuerSpec) string {
+	if spec.Region != "" {
+		return spec.Region
+	}
+	if ca, err := ParseCertificateAuthorityArn(spec.Arn); err == nil {
+		return ca.Region
+	}
+	return ""
+}
+
+// dnsSuffix returns the DNS suffix of the endpoints in a partition. The suffix
+// of the aws partition is used when the partition is unknown.
+func dnsSuffix(partition string) string {
+	if suffix, ok := partitionDNSSuffixes[partition]; ok {
+		return suffix
+	}
+	return partitionDNSSuffixes[defaultPartition]
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"testing"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCertificateAuthorityArn(t *testing.T) {
+	type testCase struct {
+		arn           string
+		expected      CertificateAuthorityArn
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"aws": {
+			arn: "arn:aws:acm-pca:us-east-1:123456789012:certificate-authority/12345678-1234-1234-1234-123456789012",
+			expected: CertificateAuthorityArn{
+				Partition: "aws",
+				Region:    "us-east-1",
+				AccountID: "123456789012",
+				ID:        "12345678-1234-1234-1234-123456789012",
+			},
+		},
+		"govcloud": {
+			arn: "arn:aws-us-gov:acm-pca:us-gov-west-1:123456789012:certificate-authority/12345678-1234-1234-1234-123456789012",
+			expected: CertificateAuthorityArn{
+				Partition: "aws-us-gov",
+				Region:    "us-gov-west-1",
+				AccountID: "123456789012",
+				ID:        "12345678-1234-1234-1234-123456789012",
+			},
+		},
+		"placeholder-account": {
+			arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+			expected: CertificateAuthorityArn{
+				Partition: "aws",
+				Region:    "us-east-1",
+				AccountID: "account",
+				ID:        "12345678-1234-1234-1234-123456789012",
+			},
+		},
+		"not-an-arn": {
+			arn:           "12345678-1234-1234-1234-123456789012",
+			expectFailure: true,
+		},
+		"other-service": {
+			arn:           "arn:aws:iam::123456789012:role/pca",
+			expectFailure: true,
+		},
+		"template": {
+			arn:           "arn:aws:acm-pca:::template/EndEntityCertificate/V1",
+			expectFailure: true,
+		},
+		"no-region": {
+			arn:           "arn:aws:acm-pca::123456789012:certificate-authority/12345678-1234-1234-1234-123456789012",
+			expectFailure: true,
+		},
+		"no-id": {
+			arn:           "arn:aws:acm-pca:us-east-1:123456789012:certificate-authority/",
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ca, err := ParseCertificateAuthorityArn(tc.arn)
+			if tc.expectFailure {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, ca)
+			assert.Equal(t, tc.arn, ca.String())
+		})
+	}
+}
+
+func TestCertificateAuthorityTemplateArn(t *testing.T) {
+	tests := map[string]string{
+		"aws":     "arn:aws:acm-pca:::template/EndEntityCertificate/V1",
+		"aws-cn":  "arn:aws-cn:acm-pca:::template/EndEntityCertificate/V1",
+		"unknown": "arn:aws:acm-pca:::template/EndEntityCertificate/V1",
+	}
+	partitions := map[string]string{"aws": "aws", "aws-cn": "aws-cn", "unknown": ""}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			ca := CertificateAuthorityArn{Partition: partitions[name]}
+			assert.Equal(t, expected, ca.TemplateArn("EndEntityCertificate/V1"))
+		})
+	}
+}
+
+func TestIssuerRegion(t *testing.T) {
+	const caArn = "arn:aws:acm-pca:eu-west-1:123456789012:certificate-authority/12345678-1234-1234-1234-123456789012"
+
+	type testCase struct {
+		spec           issuerapi.AWSPCAIssuerSpec
+		expectedRegion string
+		expectFailure  bool
+	}
+
+	tests := map[string]testCase{
+		"region-from-arn": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: caArn},
+			expectedRegion: "eu-west-1",
+		},
+		"matching-region": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: caArn, Region: "eu-west-1"},
+			expectedRegion: "eu-west-1",
+		},
+		"conflicting-region": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: caArn, Region: "us-east-1"},
+			expectedRegion: "us-east-1",
+			expectFailure:  true,
+		},
+		"invalid-arn": {
+			spec:          issuerapi.AWSPCAIssuerSpec{Arn: "certificate-authority/12345678"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRegion, issuerRegion(&tc.spec))
+
+			err := ValidateRegion(&tc.spec)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
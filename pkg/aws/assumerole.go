@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	minAssumeRoleDuration = 15 * time.Minute
+	maxAssumeRoleDuration = 12 * time.Hour
+	maxSessionNameLength  = 64
+)
+
+var sessionNameInvalidChars = regexp.MustCompile(`[^\w+=,.@-]`)
+
+// newSTSClient creates the client used to assume roles, and is replaced in tests
+var newSTSClient = func(cfg aws.Config, endpoint string) stscreds.AssumeRoleAPIClient {
+	return sts.NewFromConfig(cfg, withSTSEndpoint(endpoint))
+}
+
+// sessionNameData is given to the session name template, and describes the
+// object the credentials are configured on
+type sessionNameData struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// issuerSessionNameData describes the issuer with the given name
+func issuerSessionNameData(name types.NamespacedName) sessionNameData {
+	if name.Namespace == "" {
+		return sessionNameData{Kind: "AWSPCAClusterIssuer", Name: name.Name}
+	}
+	return sessionNameData{Kind: "AWSPCAIssuer", Name: name.Name, Namespace: name.Namespace}
+}
+
+// ValidateAssumeRoleOptions checks the parts of AssumeRoleOptions that cannot
+// be expressed in the CRD schema
+func ValidateAssumeRoleOptions(role string, opts *api.AssumeRoleOptions) error {
+	if opts == nil {
+		return nil
+	}
+	if role == "" {
+		return errors.New("assumeRoleOptions requires role to be set")
+	}
+	if opts.SessionName != "" {
+		if _, err := texttemplate.New("sessionName").Parse(opts.SessionName); err != nil {
+			return fmt.Errorf("invalid session name template: %v", err)
+		}
+	}
+	if opts.Duration != nil && (opts.Duration.Duration < minAssumeRoleDuration || opts.Duration.Duration > maxAssumeRoleDuration) {
+		return fmt.Errorf("assume role duration %s is not between %s and %s", opts.Duration.Duration, minAssumeRoleDuration, maxAssumeRoleDuration)
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range opts.Tags {
+		if tags[tag.Key] {
+			return fmt.Errorf("session tag %s is set more than once", tag.Key)
+		}
+		tags[tag.Key] = true
+	}
+	for _, key := range opts.TransitiveTagKeys {
+		if !tags[key] {
+			return fmt.Errorf("transitive tag key %s is not a session tag", key)
+		}
+	}
+	return nil
+}
+
+// ValidateRoleChain checks every hop of a role chain
+func ValidateRoleChain(role string, chain []api.RoleChainHop) error {
+	if len(chain) == 0 {
+		return nil
+	}
+	if role != "" {
+		return errors.New("role and roleChain cannot both be set")
+	}
+	for i := range chain {
+		if err := ValidateAssumeRoleOptions(chain[i].Role, &chain[i].AssumeRoleOptions); err != nil {
+			return fmt.Errorf("roleChain hop %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// assumeRoleChain assumes every role of chain in order, starting with the
+// credentials of cfg. Each hop is assumed straight away, so that a hop that
+// cannot be assumed is reported when the issuer is verified.
+func assumeRoleChain(ctx context.Context, cfg aws.Config, session sessionNameData, stsEndpoint string, chain []api.RoleChainHop) (aws.CredentialsProvider, error) {
+	for i := range chain {
+		hop := &chain[i]
+		provider, err := assumeRoleProvider(cfg, session, stsEndpoint, hop.Role, &hop.AssumeRoleOptions)
+		if err != nil {
+			return nil, fmt.Errorf("roleChain hop %d (%s): %v", i+1, hop.Role, err)
+		}
+		creds := aws.NewCredentialsCache(provider)
+		if _, err := creds.Retrieve(ctx); err != nil {
+			return nil, fmt.Errorf("failed to assume roleChain hop %d (%s): %w", i+1, hop.Role, err)
+		}
+		cfg.Credentials = creds
+	}
+	return cfg.Credentials, nil
+}
+
+// roleSessionName renders the session name template
+func roleSessionName(tmpl string, data sessionNameData) (string, error) {
+	t, err := texttemplate.New("sessionName").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid session name template: %v", err)
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render session name: %v", err)
+	}
+
+	sessionName := sessionNameInvalidChars.ReplaceAllString(b.String(), "-")
+	if len(sessionName) > maxSessionNameLength {
+		sessionName = sessionName[:maxSessionNameLength]
+	}
+	if len(sessionName) < 2 {
+		return "", fmt.Errorf("session name %q is shorter than 2 characters", sessionName)
+	}
+	return sessionName, nil
+}
+
+// assumeRoleProvider returns a provider of credentials for role, assumed
+// with the credentials of cfg at stsEndpoint, if set
+func assumeRoleProvider(cfg aws.Config, session sessionNameData, stsEndpoint string, role string, opts *api.AssumeRoleOptions) (aws.CredentialsProvider, error) {
+	var sessionName string
+	if opts != nil && opts.SessionName != "" {
+		var err error
+		if sessionName, err = roleSessionName(opts.SessionName, session); err != nil {
+			return nil, err
+		}
+	}
+
+	return stscreds.NewAssumeRoleProvider(newSTSClient(cfg, stsEndpoint), role, func(o *stscreds.AssumeRoleOptions) {
+		if opts == nil {
+			return
+		}
+		if opts.ExternalID != "" {
+			o.ExternalID = aws.String(opts.ExternalID)
+		}
+		if sessionName != "" {
+			o.RoleSessionName = sessionName
+		}
+		if opts.Duration != nil {
+			o.Duration = opts.Duration.Duration
+		}
+		for _, tag := range opts.Tags {
+			o.Tags = append(o.Tags, ststypes.Tag{Key: aws.String(tag.Key), Value: aws.String(tag.Value)})
+		}
+		o.TransitiveTagKeys = opts.TransitiveTagKeys
+		if opts.SourceIdentity != "" {
+			o.SourceIdentity = aws.String(opts.SourceIdentity)
+		}
+	}), nil
+}
@@ -0,0 +1,365 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// stubSTSClient records AssumeRole calls and answers them with fixed credentials
+type stubSTSClient struct {
+	inputs []*sts.AssumeRoleInput
+}
+
+func (s *stubSTSClient) AssumeRole(_ context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	s.inputs = append(s.inputs, params)
+	return &sts.AssumeRoleOutput{
+		Credentials: &ststypes.Credentials{
+			AccessKeyId:     aws.String("assumed-access"),
+			SecretAccessKey: aws.String("assumed-secret"),
+			SessionToken:    aws.String("assumed-session"),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func stubSTS(t *testing.T) *stubSTSClient {
+	stub := &stubSTSClient{}
+	original := newSTSClient
+	newSTSClient = func(aws.Config, string) stscreds.AssumeRoleAPIClient { return stub }
+	t.Cleanup(func() { newSTSClient = original })
+	return stub
+}
+
+func TestGetConfigAssumeRoleOptions(t *testing.T) {
+	const role = "arn:aws:iam::123456789012:role/pca"
+
+	type testCase struct {
+		name     types.NamespacedName
+		options  *issuerapi.AssumeRoleOptions
+		expected sts.AssumeRoleInput
+	}
+
+	tests := map[string]testCase{
+		"no-options": {
+			name:     types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			expected: sts.AssumeRoleInput{RoleArn: aws.String(role), DurationSeconds: aws.Int32(900)},
+		},
+		"all-options": {
+			name: types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			options: &issuerapi.AssumeRoleOptions{
+				ExternalID:  "external",
+				SessionName: "{{ .Kind }}-{{ .Namespace }}-{{ .Name }}",
+				Duration:    &metav1.Duration{Duration: 2 * time.Hour},
+				Tags: []issuerapi.SessionTag{
+					{Key: "team", Value: "pki"},
+					{Key: "cluster", Value: "prod"},
+				},
+				TransitiveTagKeys: []string{"team"},
+				SourceIdentity:    "aws-privateca-issuer",
+			},
+			expected: sts.AssumeRoleInput{
+				RoleArn:         aws.String(role),
+				RoleSessionName: aws.String("AWSPCAIssuer-ns1-issuer1"),
+				ExternalId:      aws.String("external"),
+				DurationSeconds: aws.Int32(7200),
+				Tags: []ststypes.Tag{
+					{Key: aws.String("team"), Value: aws.String("pki")},
+					{Key: aws.String("cluster"), Value: aws.String("prod")},
+				},
+				TransitiveTagKeys: []string{"team"},
+				SourceIdentity:    aws.String("aws-privateca-issuer"),
+			},
+		},
+		"cluster-issuer-session-name": {
+			name: types.NamespacedName{Name: "clusterissuer1"},
+			options: &issuerapi.AssumeRoleOptions{
+				SessionName: "{{ .Kind }}/{{ .Name }}",
+			},
+			expected: sts.AssumeRoleInput{
+				RoleArn:         aws.String(role),
+				RoleSessionName: aws.String("AWSPCAClusterIssuer-clusterissuer1"),
+				DurationSeconds: aws.Int32(900),
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			stub := stubSTS(t)
+			fakeClient := fake.NewClientBuilder().Build()
+
+			ctx := context.TODO()
+			cfg, err := GetConfig(ctx, fakeClient, tc.name, &issuerapi.AWSPCAIssuerSpec{
+				Region:            "us-east-1",
+				Role:              role,
+				AssumeRoleOptions: tc.options,
+			})
+			require.NoError(t, err)
+
+			creds, err := cfg.Credentials.Retrieve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "assumed-access", creds.AccessKeyID)
+
+			require.Len(t, stub.inputs, 1)
+			got := stub.inputs[0]
+			if tc.expected.RoleSessionName == nil {
+				// The SDK generates a session name when none is configured
+				require.NotNil(t, got.RoleSessionName)
+				got.RoleSessionName = nil
+			}
+			assert.Equal(t, tc.expected, *got)
+		})
+	}
+}
+
+func TestRoleSessionName(t *testing.T) {
+	type testCase struct {
+		template      string
+		name          types.NamespacedName
+		expected      string
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"plain": {
+			template: "pca-{{ .Namespace }}-{{ .Name }}",
+			name:     types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			expected: "pca-ns1-issuer1",
+		},
+		"sanitized": {
+			template: "pca:{{ .Namespace }}/{{ .Name }}",
+			name:     types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			expected: "pca-ns1-issuer1",
+		},
+		"truncated": {
+			template: "{{ .Name }}",
+			name:     types.NamespacedName{Name: strings.Repeat("a", 100)},
+			expected: strings.Repeat("a", 64),
+		},
+		"too-short": {
+			template:      "{{ .Namespace }}",
+			name:          types.NamespacedName{Name: "clusterissuer1"},
+			expectFailure: true,
+		},
+		"unknown-field": {
+			template:      "{{ .Cluster }}",
+			name:          types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := roleSessionName(tc.template, issuerSessionNameData(tc.name))
+			if tc.expectFailure {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestValidateAssumeRoleOptions(t *testing.T) {
+	const role = "arn:aws:iam::123456789012:role/pca"
+
+	type testCase struct {
+		role          string
+		options       *issuerapi.AssumeRoleOptions
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"nil": {
+			role: role,
+		},
+		"valid": {
+			role: role,
+			options: &issuerapi.AssumeRoleOptions{
+				SessionName:       "pca-{{ .Name }}",
+				Duration:          &metav1.Duration{Duration: time.Hour},
+				Tags:              []issuerapi.SessionTag{{Key: "team", Value: "pki"}},
+				TransitiveTagKeys: []string{"team"},
+			},
+		},
+		"no-role": {
+			options:       &issuerapi.AssumeRoleOptions{ExternalID: "external"},
+			expectFailure: true,
+		},
+		"invalid-template": {
+			role:          role,
+			options:       &issuerapi.AssumeRoleOptions{SessionName: "{{ .Name"},
+			expectFailure: true,
+		},
+		"duration-too-short": {
+			role:          role,
+			options:       &issuerapi.AssumeRoleOptions{Duration: &metav1.Duration{Duration: time.Minute}},
+			expectFailure: true,
+		},
+		"duration-too-long": {
+			role:          role,
+			options:       &issuerapi.AssumeRoleOptions{Duration: &metav1.Duration{Duration: 24 * time.Hour}},
+			expectFailure: true,
+		},
+		"duplicate-tag": {
+			role: role,
+			options: &issuerapi.AssumeRoleOptions{
+				Tags: []issuerapi.SessionTag{{Key: "team", Value: "pki"}, {Key: "team", Value: "ops"}},
+			},
+			expectFailure: true,
+		},
+		"transitive-key-not-a-tag": {
+			role:          role,
+			options:       &issuerapi.AssumeRoleOptions{TransitiveTagKeys: []string{"team"}},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateAssumeRoleOptions(tc.role, tc.options)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// chainSTSClient answers AssumeRole with credentials named after the role,
+// and records which credentials each role was assumed with
+type chainSTSClient struct {
+	caller  aws.CredentialsProvider
+	callers map[string]string
+	fail    map[string]error
+}
+
+func (c *chainSTSClient) AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	role := aws.ToString(params.RoleArn)
+	if err := c.fail[role]; err != nil {
+		return nil, err
+	}
+	caller, err := c.caller.Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.callers[role] = caller.AccessKeyID
+	return &sts.AssumeRoleOutput{
+		Credentials: &ststypes.Credentials{
+			AccessKeyId:     aws.String("access-" + role),
+			SecretAccessKey: aws.String("secret-" + role),
+			SessionToken:    aws.String("session-" + role),
+			Expiration:      aws.Time(time.Now().Add(time.Hour)),
+		},
+	}, nil
+}
+
+func TestGetConfigRoleChain(t *testing.T) {
+	type testCase struct {
+		fail            map[string]error
+		expectedCallers map[string]string
+		expectedAccess  string
+		expectedError   string
+	}
+
+	tests := map[string]testCase{
+		"success": {
+			expectedCallers: map[string]string{
+				"hub":      "base-access",
+				"ca-owner": "access-hub",
+			},
+			expectedAccess: "access-ca-owner",
+		},
+		"second-hop-fails": {
+			fail:          map[string]error{"ca-owner": errors.New("access denied")},
+			expectedError: "failed to assume roleChain hop 2 (ca-owner)",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			callers := map[string]string{}
+			original := newSTSClient
+			newSTSClient = func(cfg aws.Config, _ string) stscreds.AssumeRoleAPIClient {
+				return &chainSTSClient{caller: cfg.Credentials, callers: callers, fail: tc.fail}
+			}
+			t.Cleanup(func() { newSTSClient = original })
+
+			fakeClient := fake.NewClientBuilder().
+				WithObjects(&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"},
+					Data: map[string][]byte{
+						"AWS_ACCESS_KEY_ID":     []byte("base-access"),
+						"AWS_SECRET_ACCESS_KEY": []byte("base-secret"),
+					},
+				}).
+				Build()
+
+			ctx := context.TODO()
+			cfg, err := GetConfig(ctx, fakeClient, types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &issuerapi.AWSPCAIssuerSpec{
+				Region: "us-east-1",
+				SecretRef: issuerapi.AWSCredentialsSecretReference{
+					SecretReference: v1.SecretReference{Name: "credentials", Namespace: "ns1"},
+				},
+				RoleChain: []issuerapi.RoleChainHop{
+					{Role: "hub", AssumeRoleOptions: issuerapi.AssumeRoleOptions{ExternalID: "hub-external"}},
+					{Role: "ca-owner", AssumeRoleOptions: issuerapi.AssumeRoleOptions{ExternalID: "ca-owner-external"}},
+				},
+			})
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			creds, err := cfg.Credentials.Retrieve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAccess, creds.AccessKeyID)
+			assert.Equal(t, tc.expectedCallers, callers)
+		})
+	}
+}
+
+func TestValidateRoleChain(t *testing.T) {
+	hop := issuerapi.RoleChainHop{Role: "arn:aws:iam::123456789012:role/hub"}
+
+	assert.NoError(t, ValidateRoleChain("", nil))
+	assert.NoError(t, ValidateRoleChain("", []issuerapi.RoleChainHop{hop, hop}))
+	assert.Error(t, ValidateRoleChain("arn:aws:iam::123456789012:role/pca", []issuerapi.RoleChainHop{hop}))
+	assert.Error(t, ValidateRoleChain("", []issuerapi.RoleChainHop{
+		hop,
+		{Role: "arn:aws:iam::123456789012:role/pca", AssumeRoleOptions: issuerapi.AssumeRoleOptions{TransitiveTagKeys: []string{"team"}}},
+	}))
+}
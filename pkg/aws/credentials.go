@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const secretCredentialsSource = "KubernetesSecret"
+
+// Keys of the credentials Secret used when no key is selected
+const (
+	DefaultAccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	DefaultSecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	DefaultSessionTokenKey    = "AWS_SESSION_TOKEN"
+	DefaultExpirationKey      = "AWS_SESSION_EXPIRATION"
+)
+
+// credentialsExpiryWindow is how long before temporary credentials expire the
+// secret is read again
+const credentialsExpiryWindow = 5 * time.Minute
+
+var (
+	ErrNoSessionToken = errors.New("no AWS Session Token Found")
+	ErrNoExpiration   = errors.New("no AWS Session Expiration Found")
+)
+
+// secretCredentialsProvider retrieves credentials from the Secret referenced by
+// an issuer's secretRef. The Secret is read on every call to Retrieve, so it
+// is expected to be wrapped in an aws.CredentialsCache.
+type secretCredentialsProvider struct {
+	client client.Client
+	ref    api.AWSCredentialsSecretReference
+}
+
+func newSecretCredentialsProvider(client client.Client, ref api.AWSCredentialsSecretReference) *secretCredentialsProvider {
+	return &secretCredentialsProvider{client: client, ref: ref}
+}
+
+// Retrieve reads the access key, secret key and, for temporary credentials,
+// the session token and expiry time from the Secret
+func (p *secretCredentialsProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	secretNamespaceName := types.NamespacedName{
+		Namespace: p.ref.Namespace,
+		Name:      p.ref.Name,
+	}
+
+	secret := new(core.Secret)
+	if err := p.client.Get(ctx, secretNamespaceName, secret); err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to retrieve secret: %v", err)
+	}
+
+	accessKey, ok := secretValue(secret, p.ref.AccessKeyIDSelector, DefaultAccessKeyIDKey)
+	if !ok {
+		return aws.Credentials{}, ErrNoAccessKeyID
+	}
+
+	secretKey, ok := secretValue(secret, p.ref.SecretAccessKeySelector, DefaultSecretAccessKeyKey)
+	if !ok {
+		return aws.Credentials{}, ErrNoSecretAccessKey
+	}
+
+	creds := aws.Credentials{
+		AccessKeyID:     accessKey,
+		SecretAccessKey: secretKey,
+		Source:          secretCredentialsSource,
+	}
+
+	// The default keys are optional, but selected keys must exist
+	sessionToken, ok := secretValue(secret, p.ref.SessionTokenSelector, DefaultSessionTokenKey)
+	if !ok && p.ref.SessionTokenSelector.Key != "" {
+		return aws.Credentials{}, ErrNoSessionToken
+	}
+	creds.SessionToken = sessionToken
+
+	expiration, ok := secretValue(secret, p.ref.ExpirationSelector, DefaultExpirationKey)
+	if !ok && p.ref.ExpirationSelector.Key != "" {
+		return aws.Credentials{}, ErrNoExpiration
+	}
+	if expiration != "" {
+		expires, err := time.Parse(time.RFC3339, expiration)
+		if err != nil {
+			return aws.Credentials{}, fmt.Errorf("failed to parse AWS Session Expiration: %v", err)
+		}
+		creds.CanExpire = true
+		creds.Expires = expires
+	}
+
+	return creds, nil
+}
+
+// secretValue returns the value of the selected key, or of defaultKey when no key is selected
+func secretValue(secret *core.Secret, selector core.SecretKeySelector, defaultKey string) (string, bool) {
+	key := defaultKey
+	if selector.Key != "" {
+		key = selector.Key
+	}
+	value, ok := secret.Data[key]
+	return string(value), ok
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSecretCredentialsProvider(t *testing.T) {
+	type testCase struct {
+		ref             issuerapi.AWSCredentialsSecretReference
+		data            map[string][]byte
+		expectedToken   string
+		expectedExpires time.Time
+		expectedError   error
+		expectFailure   bool
+	}
+
+	secretRef := issuerapi.AWSCredentialsSecretReference{
+		SecretReference: v1.SecretReference{Name: "credentials", Namespace: "ns1"},
+	}
+	withSelectors := secretRef
+	withSelectors.SessionTokenSelector = v1.SecretKeySelector{Key: "token"}
+	withSelectors.ExpirationSelector = v1.SecretKeySelector{Key: "expiration"}
+
+	tests := map[string]testCase{
+		"long-term-credentials": {
+			ref: secretRef,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("access"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+			},
+		},
+		"default-session-keys": {
+			ref: secretRef,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":      []byte("access"),
+				"AWS_SECRET_ACCESS_KEY":  []byte("secret"),
+				"AWS_SESSION_TOKEN":      []byte("session"),
+				"AWS_SESSION_EXPIRATION": []byte("2030-01-02T03:04:05Z"),
+			},
+			expectedToken:   "session",
+			expectedExpires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		"selected-session-keys": {
+			ref: withSelectors,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("access"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+				"token":                 []byte("session"),
+				"expiration":            []byte("2030-01-02T03:04:05Z"),
+			},
+			expectedToken:   "session",
+			expectedExpires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		"missing-selected-session-token": {
+			ref: withSelectors,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("access"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+				"expiration":            []byte("2030-01-02T03:04:05Z"),
+			},
+			expectedError: ErrNoSessionToken,
+		},
+		"missing-selected-expiration": {
+			ref: withSelectors,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("access"),
+				"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+				"token":                 []byte("session"),
+			},
+			expectedError: ErrNoExpiration,
+		},
+		"invalid-expiration": {
+			ref: secretRef,
+			data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":      []byte("access"),
+				"AWS_SECRET_ACCESS_KEY":  []byte("secret"),
+				"AWS_SESSION_EXPIRATION": []byte("tomorrow"),
+			},
+			expectFailure: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"},
+					Data:       tc.data,
+				}).
+				Build()
+
+			creds, err := newSecretCredentialsProvider(fakeClient, tc.ref).Retrieve(context.TODO())
+			if tc.expectedError != nil {
+				assert.Equal(t, tc.expectedError, err)
+				return
+			}
+			if tc.expectFailure {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "access", creds.AccessKeyID)
+			assert.Equal(t, "secret", creds.SecretAccessKey)
+			assert.Equal(t, tc.expectedToken, creds.SessionToken)
+			assert.Equal(t, !tc.expectedExpires.IsZero(), creds.CanExpire)
+			assert.True(t, tc.expectedExpires.Equal(creds.Expires))
+		})
+	}
+}
+
+func TestGetConfigRereadsExpiringCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"},
+		Data: map[string][]byte{
+			"AWS_ACCESS_KEY_ID":      []byte("access1"),
+			"AWS_SECRET_ACCESS_KEY":  []byte("secret1"),
+			"AWS_SESSION_TOKEN":      []byte("session1"),
+			"AWS_SESSION_EXPIRATION": []byte(time.Now().Add(time.Minute).UTC().Format(time.RFC3339)),
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	ctx := context.TODO()
+	cfg, err := GetConfig(ctx, fakeClient, types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &issuerapi.AWSPCAIssuerSpec{
+		Region: "us-east-1",
+		SecretRef: issuerapi.AWSCredentialsSecretReference{
+			SecretReference: v1.SecretReference{Name: "credentials", Namespace: "ns1"},
+		},
+	})
+	require.NoError(t, err)
+
+	creds, err := cfg.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "session1", creds.SessionToken)
+
+	// The credentials expire within the expiry window, so the rotated secret is read
+	secret.Data = map[string][]byte{
+		"AWS_ACCESS_KEY_ID":      []byte("access2"),
+		"AWS_SECRET_ACCESS_KEY":  []byte("secret2"),
+		"AWS_SESSION_TOKEN":      []byte("session2"),
+		"AWS_SESSION_EXPIRATION": []byte(time.Now().Add(time.Hour).UTC().Format(time.RFC3339)),
+	}
+	require.NoError(t, fakeClient.Update(ctx, secret))
+
+	creds, err = cfg.Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "access2", creds.AccessKeyID)
+	assert.Equal(t, "session2", creds.SessionToken)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/acmpca"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+)
+
+// ValidateEndpoints checks that the endpoints of an issuer are absolute URLs
+func ValidateEndpoints(endpoints *api.AWSEndpoints) error {
+	if endpoints == nil {
+		return nil
+	}
+	for service, endpoint := range map[string]string{"acmPCA": endpoints.ACMPCA, "sts": endpoints.STS, "rolesAnywhere": endpoints.RolesAnywhere} {
+		if endpoint == "" {
+			continue
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("invalid %s endpoint: %v", service, err)
+		}
+		if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("invalid %s endpoint %q: must be an http or https URL", service, endpoint)
+		}
+	}
+	return nil
+}
+
+// endpointConfigOptions returns the config options selecting FIPS and
+// dual-stack endpoints for an issuer
+func endpointConfigOptions(spec *api.AWSPCAIssuerSpec) []func(*config.LoadOptions) error {
+	var options []func(*config.LoadOptions) error
+	if spec.UseFIPS {
+		options = append(options, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if spec.UseDualStack {
+		options = append(options, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	return options
+}
+
+func acmPCAEndpoint(spec *api.AWSPCAIssuerSpec) string {
+	if spec.Endpoints == nil {
+		return ""
+	}
+	return spec.Endpoints.ACMPCA
+}
+
+func stsEndpoint(spec *api.AWSPCAIssuerSpec) string {
+	if spec.Endpoints == nil {
+		return ""
+	}
+	return spec.Endpoints.STS
+}
+
+func withACMPCAEndpoint(endpoint string) func(*acmpca.Options) {
+	return func(o *acmpca.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}
+}
+
+func withSTSEndpoint(endpoint string) func(*sts.Options) {
+	return func(o *sts.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	}
+}
+
+// NewSTSClient returns an STS client using the STS endpoint of an issuer
+func NewSTSClient(cfg aws.Config, spec *api.AWSPCAIssuerSpec) *sts.Client {
+	return sts.NewFromConfig(cfg, withSTSEndpoint(stsEndpoint(spec)))
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acmpca"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateEndpoints(t *testing.T) {
+	type testCase struct {
+		endpoints     *issuerapi.AWSEndpoints
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"nil": {},
+		"valid": {
+			endpoints: &issuerapi.AWSEndpoints{
+				ACMPCA:        "https://vpce-0123.acm-pca.us-east-1.vpce.amazonaws.com",
+				STS:           "http://localhost:4566",
+				RolesAnywhere: "https://rolesanywhere.eu-west-1.amazonaws.com",
+			},
+		},
+		"relative": {
+			endpoints:     &issuerapi.AWSEndpoints{ACMPCA: "acm-pca.us-east-1.amazonaws.com"},
+			expectFailure: true,
+		},
+		"unsupported-scheme": {
+			endpoints:     &issuerapi.AWSEndpoints{STS: "ftp://sts.example.com"},
+			expectFailure: true,
+		},
+		"invalid-roles-anywhere": {
+			endpoints:     &issuerapi.AWSEndpoints{RolesAnywhere: "rolesanywhere.eu-west-1.amazonaws.com"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateEndpoints(tc.endpoints)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestGetProvisionerEndpoints points both the STS and ACM PCA clients at a
+// local stand-in, and checks that the role assumed through STS is used to call ACM PCA
+func TestGetProvisionerEndpoints(t *testing.T) {
+	var pcaAuthorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if target := r.Header.Get("X-Amz-Target"); target != "" {
+			assert.Equal(t, "ACMPrivateCA.DescribeCertificateAuthority", target)
+			pcaAuthorization = r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			_, _ = w.Write([]byte(`{"CertificateAuthority":{"Status":"ACTIVE"}}`))
+			return
+		}
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "AssumeRole", r.PostForm.Get("Action"))
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleResult>
+    <Credentials>
+      <AccessKeyId>assumed-access</AccessKeyId>
+      <SecretAccessKey>assumed-secret</SecretAccessKey>
+      <SessionToken>assumed-session</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleResult>
+</AssumeRoleResponse>`))
+	}))
+	defer server.Close()
+
+	fakeClient := fake.NewClientBuilder().
+		WithObjects(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"},
+			Data: map[string][]byte{
+				"AWS_ACCESS_KEY_ID":     []byte("base-access"),
+				"AWS_SECRET_ACCESS_KEY": []byte("base-secret"),
+			},
+		}).
+		Build()
+
+	name := types.NamespacedName{Namespace: "ns1", Name: "endpoints-issuer"}
+	t.Cleanup(func() { DeleteProvisioner(context.TODO(), fakeClient, name) })
+
+	ctx := context.TODO()
+	provisioner, err := GetProvisioner(ctx, fakeClient, name, &issuerapi.AWSPCAIssuerSpec{
+		Arn:    arn,
+		Region: "us-east-1",
+		Role:   "arn:aws:iam::123456789012:role/pca",
+		SecretRef: issuerapi.AWSCredentialsSecretReference{
+			SecretReference: v1.SecretReference{Name: "credentials", Namespace: "ns1"},
+		},
+		Endpoints: &issuerapi.AWSEndpoints{
+			ACMPCA: server.URL,
+			STS:    server.URL,
+		},
+	})
+	require.NoError(t, err)
+
+	ca, err := provisioner.DescribeCertificateAuthority(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, acmpcatypes.CertificateAuthorityStatusActive, ca.Status)
+	assert.True(t, strings.Contains(pcaAuthorization, "Credential=assumed-access/"), "ACM PCA was not called with the assumed role: %s", pcaAuthorization)
+}
+
+func TestGetProvisionerFIPSAndDualStack(t *testing.T) {
+	fakeClient := fake.NewClientBuilder().Build()
+	name := types.NamespacedName{Namespace: "ns1", Name: "fips-issuer"}
+	t.Cleanup(func() { DeleteProvisioner(context.TODO(), fakeClient, name) })
+
+	provisioner, err := GetProvisioner(context.TODO(), fakeClient, name, &issuerapi.AWSPCAIssuerSpec{
+		Arn:          arn,
+		Region:       "us-east-1",
+		UseFIPS:      true,
+		UseDualStack: true,
+	})
+	require.NoError(t, err)
+
+	options := provisioner.(*PCAProvisioner).pcaClient.(*acmpca.Client).Options()
+	assert.Equal(t, aws.FIPSEndpointStateEnabled, options.EndpointOptions.UseFIPSEndpoint)
+	assert.Equal(t, aws.DualStackEndpointStateEnabled, options.EndpointOptions.UseDualStackEndpoint)
+	assert.Nil(t, options.BaseEndpoint)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrorClass tells whether an operation that failed may succeed when retried
+type ErrorClass int
+
+const (
+	// ErrorClassRetryable errors are expected to go away, e.g. throttling,
+	// network errors or permissions that have not propagated yet
+	ErrorClassRetryable ErrorClass = iota
+	// ErrorClassPermanent errors fail again for the same request, e.g. a
+	// malformed CSR or arguments PCA rejects
+	ErrorClassPermanent
+)
+
+func (c ErrorClass) String() string {
+	if c == ErrorClassPermanent {
+		return "Permanent"
+	}
+	return "Retryable"
+}
+
+// errorCodeClasses classifies the error codes of ACM PCA and STS. Codes of
+// both services are listed together, since they do not overlap in meaning.
+var errorCodeClasses = map[string]ErrorClass{
+	// Throttling and quotas
+	"ThrottlingException":        ErrorClassRetryable,
+	"Throttling":                 ErrorClassRetryable,
+	"TooManyRequestsException":   ErrorClassRetryable,
+	"LimitExceededException":     ErrorClassRetryable,
+	"RequestLimitExceeded":       ErrorClassRetryable,
+	"ServiceQuotaExceeded":       ErrorClassRetryable,
+	"RequestInProgressException": ErrorClassRetryable,
+
+	// Service side failures
+	"InternalFailure":                 ErrorClassRetryable,
+	"InternalServerError":             ErrorClassRetryable,
+	"ServiceUnavailable":              ErrorClassRetryable,
+	"ServiceUnavailableException":     ErrorClassRetryable,
+	"RequestFailedException":          ErrorClassRetryable, // Permanent from GetCertificate, see ErrIssuanceFailed
+	"ConcurrentModificationException": ErrorClassRetryable,
+	"IDPCommunicationError":           ErrorClassRetryable,
+
+	// Credentials and permissions, which may be propagating or being rotated
+	"AccessDenied":                ErrorClassRetryable,
+	"AccessDeniedException":       ErrorClassRetryable,
+	"ExpiredToken":                ErrorClassRetryable,
+	"ExpiredTokenException":       ErrorClassRetryable,
+	"InvalidClientTokenId":        ErrorClassRetryable,
+	"UnrecognizedClientException": ErrorClassRetryable,
+	"InvalidIdentityToken":        ErrorClassRetryable,
+
+	// Requests PCA will not accept
+	"MalformedCSRException":            ErrorClassPermanent,
+	"MalformedCertificateException":    ErrorClassPermanent,
+	"InvalidArgsException":             ErrorClassPermanent,
+	"InvalidArnException":              ErrorClassPermanent,
+	"InvalidStateException":            ErrorClassPermanent,
+	"InvalidRequestException":          ErrorClassPermanent,
+	"InvalidPolicyException":           ErrorClassPermanent,
+	"ResourceNotFoundException":        ErrorClassPermanent,
+	"RequestAlreadyProcessedException": ErrorClassPermanent,
+	"ValidationException":              ErrorClassPermanent,
+
+	// STS requests that cannot succeed
+	"MalformedPolicyDocument": ErrorClassPermanent,
+	"PackedPolicyTooLarge":    ErrorClassPermanent,
+	"RegionDisabledException": ErrorClassPermanent,
+}
+
+// ClassifyError tells whether an operation that failed with err may succeed
+// when retried. Errors raised by the issuer for the request itself, and the
+// known permanent error codes of ACM PCA and STS, are permanent. Other AWS
+// errors are permanent only when they are the caller's fault. Everything
+// else, such as network errors and timeouts, is retryable.
+func ClassifyError(err error) ErrorClass {
+	if errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrIssuancePolicy) || errors.Is(err, ErrSigningAlgorithm) ||
+		errors.Is(err, ErrInvalidDuration) || errors.Is(err, ErrValidityExceedsCA) || errors.Is(err, ErrIssuanceFailed) {
+		return ErrorClassPermanent
+	}
+
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return ErrorClassRetryable
+	}
+	if class, ok := errorCodeClasses[apiErr.ErrorCode()]; ok {
+		return class
+	}
+	if apiErr.ErrorFault() == smithy.FaultClient {
+		return ErrorClassPermanent
+	}
+	return ErrorClassRetryable
+}
+
+// IsPermanentError reports whether err is in ErrorClassPermanent
+func IsPermanentError(err error) bool {
+	return ClassifyError(err) == ErrorClassPermanent
+}
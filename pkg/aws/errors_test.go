@@ -0,0 +1,161 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	type testCase struct {
+		err      error
+		expected ErrorClass
+	}
+
+	tests := map[string]testCase{
+		"pca-throttling": {
+			err:      &smithy.GenericAPIError{Code: "ThrottlingException", Fault: smithy.FaultClient},
+			expected: ErrorClassRetryable,
+		},
+		"sts-throttling": {
+			err:      &smithy.GenericAPIError{Code: "Throttling", Fault: smithy.FaultClient},
+			expected: ErrorClassRetryable,
+		},
+		"pca-limit-exceeded": {
+			err:      &acmpcatypes.LimitExceededException{},
+			expected: ErrorClassRetryable,
+		},
+		"pca-request-in-progress": {
+			err:      &acmpcatypes.RequestInProgressException{},
+			expected: ErrorClassRetryable,
+		},
+		"pca-request-failed": {
+			err:      &acmpcatypes.RequestFailedException{},
+			expected: ErrorClassRetryable,
+		},
+		"pca-concurrent-modification": {
+			err:      &acmpcatypes.ConcurrentModificationException{},
+			expected: ErrorClassRetryable,
+		},
+		"pca-access-denied": {
+			err:      &smithy.GenericAPIError{Code: "AccessDeniedException", Fault: smithy.FaultClient},
+			expected: ErrorClassRetryable,
+		},
+		"sts-access-denied": {
+			err:      &smithy.GenericAPIError{Code: "AccessDenied", Fault: smithy.FaultClient},
+			expected: ErrorClassRetryable,
+		},
+		"sts-expired-token": {
+			err:      &ststypes.ExpiredTokenException{},
+			expected: ErrorClassRetryable,
+		},
+		"sts-idp-communication": {
+			err:      &ststypes.IDPCommunicationErrorException{},
+			expected: ErrorClassRetryable,
+		},
+		"unknown-server-fault": {
+			err:      &smithy.GenericAPIError{Code: "SomethingBroke", Fault: smithy.FaultServer},
+			expected: ErrorClassRetryable,
+		},
+		"unknown-fault": {
+			err:      &smithy.GenericAPIError{Code: "SomethingHappened"},
+			expected: ErrorClassRetryable,
+		},
+		"network": {
+			err:      &net.OpError{Op: "dial", Err: errors.New("connection refused")},
+			expected: ErrorClassRetryable,
+		},
+		"deadline-exceeded": {
+			err:      context.DeadlineExceeded,
+			expected: ErrorClassRetryable,
+		},
+		"pca-malformed-csr": {
+			err:      &acmpcatypes.MalformedCSRException{},
+			expected: ErrorClassPermanent,
+		},
+		"pca-invalid-args": {
+			err:      &acmpcatypes.InvalidArgsException{},
+			expected: ErrorClassPermanent,
+		},
+		"pca-invalid-state": {
+			err:      &acmpcatypes.InvalidStateException{},
+			expected: ErrorClassPermanent,
+		},
+		"pca-invalid-arn": {
+			err:      &acmpcatypes.InvalidArnException{},
+			expected: ErrorClassPermanent,
+		},
+		"pca-resource-not-found": {
+			err:      &acmpcatypes.ResourceNotFoundException{},
+			expected: ErrorClassPermanent,
+		},
+		"sts-malformed-policy": {
+			err:      &ststypes.MalformedPolicyDocumentException{},
+			expected: ErrorClassPermanent,
+		},
+		"sts-region-disabled": {
+			err:      &ststypes.RegionDisabledException{},
+			expected: ErrorClassPermanent,
+		},
+		"unknown-client-fault": {
+			err:      &smithy.GenericAPIError{Code: "SomethingWrong", Fault: smithy.FaultClient},
+			expected: ErrorClassPermanent,
+		},
+		"wrapped-api-error": {
+			err:      fmt.Errorf("operation error ACM PCA: IssueCertificate: %w", &acmpcatypes.MalformedCSRException{}),
+			expected: ErrorClassPermanent,
+		},
+		"invalid-request": {
+			err:      fmt.Errorf("%w: failed to decode CSR", ErrInvalidRequest),
+			expected: ErrorClassPermanent,
+		},
+		"issuance-policy": {
+			err:      fmt.Errorf("%w: CA certificates are not allowed", ErrIssuancePolicy),
+			expected: ErrorClassPermanent,
+		},
+		"pca-request-failed-on-get": {
+			err:      fmt.Errorf("%w: %v", ErrIssuanceFailed, &acmpcatypes.RequestFailedException{}),
+			expected: ErrorClassPermanent,
+		},
+		"signing-algorithm": {
+			err:      fmt.Errorf("%w: signing algorithm SHA256WITHRSA cannot be used with the EC_prime256v1 key of the certificate authority", ErrSigningAlgorithm),
+			expected: ErrorClassPermanent,
+		},
+		"invalid-duration": {
+			err:      fmt.Errorf("%w: the requested duration 0s is not positive", ErrInvalidDuration),
+			expected: ErrorClassPermanent,
+		},
+		"validity-exceeds-ca": {
+			err:      fmt.Errorf("%w: certificate authority expires first", ErrValidityExceedsCA),
+			expected: ErrorClassPermanent,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ClassifyError(tc.err))
+			assert.Equal(t, tc.expected == ErrorClassPermanent, IsPermanentError(tc.err))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+)
+
+// ValidateApiPassthrough checks the parts of an ApiPassthrough that cannot be
+// expressed in the CRD schema
+func ValidateApiPassthrough(passthrough *api.ApiPassthrough) error {
+	if passthrough == nil {
+		return nil
+	}
+
+	if passthrough.Extensions != nil {
+		for _, extension := range passthrough.Extensions.CustomExtensions {
+			if _, err := base64.StdEncoding.DecodeString(extension.Value); err != nil {
+				return fmt.Errorf("custom extension %s does not have a base64 encoded value: %v", extension.ObjectIdentifier, err)
+			}
+		}
+	}
+
+	if subject := passthrough.Subject; subject != nil && len(subject.CustomAttributes) > 0 {
+		standard := []string{
+			subject.CommonName, subject.Country, subject.State, subject.Locality,
+			subject.Organization, subject.OrganizationalUnit, subject.SerialNumber, subject.Title,
+			subject.GivenName, subject.Surname, subject.Initials, subject.Pseudonym,
+			subject.GenerationQualifier, subject.DistinguishedNameQualifier,
+		}
+		if strings.Join(standard, "") != "" {
+			return fmt.Errorf("subject custom attributes cannot be combined with standard subject attributes")
+		}
+	}
+
+	return nil
+}
+
+// apiPassthroughTemplateArn returns the APIPassthrough variant of a template.
+// Templates that already pass through API values are returned unchanged.
+func apiPassthroughTemplateArn(template string) string {
+	if strings.Contains(template, "Passthrough") {
+		return template
+	}
+
+	idx := strings.LastIndex(template, "/")
+	return template[:idx] + "_APIPassthrough" + template[idx:]
+}
+
+// toApiPassthrough converts the issuer's ApiPassthrough into its PCA representation
+func toApiPassthrough(passthrough *api.ApiPassthrough) *acmpcatypes.ApiPassthrough {
+	if passthrough == nil {
+		return nil
+	}
+
+	out := &acmpcatypes.ApiPassthrough{}
+
+	if extensions := passthrough.Extensions; extensions != nil {
+		out.Extensions = &acmpcatypes.Extensions{}
+		for _, policy := range extensions.CertificatePolicies {
+			info := acmpcatypes.PolicyInformation{
+				CertPolicyId: aws.String(policy.PolicyID),
+			}
+			if policy.CPSUri != "" {
+				info.PolicyQualifiers = []acmpcatypes.PolicyQualifierInfo{
+					{
+						PolicyQualifierId: acmpcatypes.PolicyQualifierIdCps,
+						Qualifier:         &acmpcatypes.Qualifier{CpsUri: aws.String(policy.CPSUri)},
+					},
+				}
+			}
+			out.Extensions.CertificatePolicies = append(out.Extensions.CertificatePolicies, info)
+		}
+		for _, oid := range extensions.ExtendedKeyUsages {
+			out.Extensions.ExtendedKeyUsage = append(out.Extensions.ExtendedKeyUsage, acmpcatypes.ExtendedKeyUsage{
+				ExtendedKeyUsageObjectIdentifier: aws.String(oid),
+			})
+		}
+		for _, extension := range extensions.CustomExtensions {
+			out.Extensions.CustomExtensions = append(out.Extensions.CustomExtensions, acmpcatypes.CustomExtension{
+				ObjectIdentifier: aws.String(extension.ObjectIdentifier),
+				Value:            aws.String(extension.Value),
+				Critical:         aws.Bool(extension.Critical),
+			})
+		}
+	}
+
+	if subject := passthrough.Subject; subject != nil {
+		out.Subject = &acmpcatypes.ASN1Subject{
+			CommonName:                 optionalString(subject.CommonName),
+			Country:                    optionalString(subject.Country),
+			State:                      optionalString(subject.State),
+			Locality:                   optionalString(subject.Locality),
+			Organization:               optionalString(subject.Organization),
+			OrganizationalUnit:         optionalString(subject.OrganizationalUnit),
+			SerialNumber:               optionalString(subject.SerialNumber),
+			Title:                      optionalString(subject.Title),
+			GivenName:                  optionalString(subject.GivenName),
+			Surname:                    optionalString(subject.Surname),
+			Initials:                   optionalString(subject.Initials),
+			Pseudonym:                  optionalString(subject.Pseudonym),
+			GenerationQualifier:        optionalString(subject.GenerationQualifier),
+			DistinguishedNameQualifier: optionalString(subject.DistinguishedNameQualifier),
+		}
+		for _, attribute := range subject.CustomAttributes {
+			out.Subject.CustomAttributes = append(out.Subject.CustomAttributes, acmpcatypes.CustomAttribute{
+				ObjectIdentifier: aws.String(attribute.ObjectIdentifier),
+				Value:            aws.String(attribute.Value),
+			})
+		}
+	}
+
+	return out
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
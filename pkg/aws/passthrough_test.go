@@ -0,0 +1,161 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateApiPassthrough(t *testing.T) {
+	type testCase struct {
+		passthrough   *issuerapi.ApiPassthrough
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"nil": {
+			passthrough: nil,
+		},
+		"valid": {
+			passthrough: &issuerapi.ApiPassthrough{
+				Extensions: &issuerapi.ApiPassthroughExtensions{
+					CustomExtensions: []issuerapi.CustomExtension{
+						{ObjectIdentifier: "1.2.3.4", Value: "BQA=", Critical: true},
+					},
+				},
+				Subject: &issuerapi.ApiPassthroughSubject{
+					CommonName:   "example.com",
+					Organization: "Example",
+				},
+			},
+		},
+		"custom extension not base64": {
+			passthrough: &issuerapi.ApiPassthrough{
+				Extensions: &issuerapi.ApiPassthroughExtensions{
+					CustomExtensions: []issuerapi.CustomExtension{
+						{ObjectIdentifier: "1.2.3.4", Value: "not base64!"},
+					},
+				},
+			},
+			expectFailure: true,
+		},
+		"custom attributes with standard attributes": {
+			passthrough: &issuerapi.ApiPassthrough{
+				Subject: &issuerapi.ApiPassthroughSubject{
+					CommonName: "example.com",
+					CustomAttributes: []issuerapi.SubjectCustomAttribute{
+						{ObjectIdentifier: "2.5.4.3", Value: "example.com"},
+					},
+				},
+			},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateApiPassthrough(tc.passthrough)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestApiPassthroughTemplateArn(t *testing.T) {
+	tests := map[string]string{
+		"arn:aws:acm-pca:::template/EndEntityCertificate/V1":                        "arn:aws:acm-pca:::template/EndEntityCertificate_APIPassthrough/V1",
+		"arn:aws:acm-pca:::template/EndEntityServerAuthCertificate/V1":              "arn:aws:acm-pca:::template/EndEntityServerAuthCertificate_APIPassthrough/V1",
+		"arn:aws:acm-pca:::template/SubordinateCACertificate_PathLen0/V1":           "arn:aws:acm-pca:::template/SubordinateCACertificate_PathLen0_APIPassthrough/V1",
+		"arn:aws:acm-pca:::template/BlankEndEntityCertificate_APICSRPassthrough/V1": "arn:aws:acm-pca:::template/BlankEndEntityCertificate_APICSRPassthrough/V1",
+	}
+
+	for template, expected := range tests {
+		t.Run(template, func(t *testing.T) {
+			assert.Equal(t, expected, apiPassthroughTemplateArn(template))
+		})
+	}
+}
+
+func TestPCASignApiPassthrough(t *testing.T) {
+	client := &workingACMPCAClient{}
+	provisioner := PCAProvisioner{
+		arn:       arn,
+		pcaClient: client,
+		apiPassthrough: toApiPassthrough(&issuerapi.ApiPassthrough{
+			Extensions: &issuerapi.ApiPassthroughExtensions{
+				CertificatePolicies: []issuerapi.CertificatePolicy{
+					{PolicyID: "2.23.140.1.2.1", CPSUri: "https://example.com/cps"},
+				},
+				ExtendedKeyUsages: []string{"1.3.6.1.5.5.7.3.17"},
+				CustomExtensions: []issuerapi.CustomExtension{
+					{ObjectIdentifier: "1.2.3.4", Value: "BQA=", Critical: true},
+				},
+			},
+			Subject: &issuerapi.ApiPassthroughSubject{
+				Organization: "Example",
+			},
+		}),
+	}
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	csrBytes, _ := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	cr := &cmapi.CertificateRequest{
+		Spec: cmapi.CertificateRequestSpec{
+			Request: pem.EncodeToMemory(&pem.Block{
+				Bytes: csrBytes,
+				Type:  "CERTIFICATE REQUEST",
+			}),
+			Usages: []cmapi.KeyUsage{cmapi.UsageServerAuth},
+		},
+	}
+
+	require.NoError(t, provisioner.Sign(context.TODO(), cr, logr.Discard()))
+
+	got := client.issueCertInput
+	require.NotNil(t, got)
+	assert.Equal(t, "arn:aws:acm-pca:::template/EndEntityServerAuthCertificate_APIPassthrough/V1", *got.TemplateArn)
+	require.NotNil(t, got.ApiPassthrough)
+	assert.Equal(t, []acmpcatypes.PolicyInformation{
+		{
+			CertPolicyId: aws.String("2.23.140.1.2.1"),
+			PolicyQualifiers: []acmpcatypes.PolicyQualifierInfo{
+				{
+					PolicyQualifierId: acmpcatypes.PolicyQualifierIdCps,
+					Qualifier:         &acmpcatypes.Qualifier{CpsUri: aws.String("https://example.com/cps")},
+				},
+			},
+		},
+	}, got.ApiPassthrough.Extensions.CertificatePolicies)
+	assert.Equal(t, "1.3.6.1.5.5.7.3.17", *got.ApiPassthrough.Extensions.ExtendedKeyUsage[0].ExtendedKeyUsageObjectIdentifier)
+	assert.True(t, *got.ApiPassthrough.Extensions.CustomExtensions[0].Critical)
+	assert.Equal(t, "Example", *got.ApiPassthrough.Subject.Organization)
+	assert.Nil(t, got.ApiPassthrough.Subject.CommonName)
+}
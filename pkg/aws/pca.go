@@ -20,9 +20,12 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"crypto/x509"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -31,34 +34,50 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws/middleware"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/acmpca"
 	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
-	"github.com/aws/aws-sdk-go-v2/service/sts"
 	injections "github.com/cert-manager/aws-privateca-issuer/pkg/api/injections"
 	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	"github.com/go-logr/logr"
-	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const DEFAULT_DURATION = 30 * 24 * 3600
+const (
+	// CertificateArnAnnotation records the ARN of the certificate issued for a CertificateRequest
+	CertificateArnAnnotation = "aws-privateca-issuer/certificate-arn"
+	// TemplateArnAnnotation lets a CertificateRequest select one of the templates
+	// allowed by its issuer
+	TemplateArnAnnotation = "aws-privateca-issuer/template-arn"
+	// ValidityShortenedAnnotation records why the certificate issued for a
+	// CertificateRequest is valid for less time than it requested
+	ValidityShortenedAnnotation = "aws-privateca-issuer/validity-shortened"
+)
 
 var (
 	ErrNoSecretAccessKey = errors.New("no AWS Secret Access Key Found")
 	ErrNoAccessKeyID     = errors.New("no AWS Access Key ID Found")
 )
 
+// ErrIssuanceFailed is returned when PCA reports that it failed to issue the
+// certificate of a CertificateRequest, which retrieving it again cannot change
+var ErrIssuanceFailed = errors.New("certificate authority failed to issue the certificate")
+
+var (
+	templateArnRegexp  = regexp.MustCompile(`^arn:[^:]+:acm-pca:::template/[A-Za-z0-9_]+/V[0-9]+$`)
+	templateNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_]+/V[0-9]+$`)
+)
+
 var collection = new(sync.Map)
 
 // GenericProvisioner abstracts over the Provisioner type for mocking purposes
 type GenericProvisioner interface {
 	Get(ctx context.Context, cr *cmapi.CertificateRequest, certArn string, log logr.Logger) ([]byte, []byte, error)
 	Sign(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) error
+	Revoke(ctx context.Context, cr *cmapi.CertificateRequest, certArn string, reason acmpcatypes.RevocationReason, log logr.Logger) error
+	DescribeCertificateAuthority(ctx context.Context) (*acmpcatypes.CertificateAuthority, error)
 }
 
 // acmPCAClient abstracts over the methods used from acmpca.Client
@@ -66,18 +85,27 @@ type acmPCAClient interface {
 	acmpca.GetCertificateAPIClient
 	DescribeCertificateAuthority(ctx context.Context, params *acmpca.DescribeCertificateAuthorityInput, optFns ...func(*acmpca.Options)) (*acmpca.DescribeCertificateAuthorityOutput, error)
 	IssueCertificate(ctx context.Context, params *acmpca.IssueCertificateInput, optFns ...func(*acmpca.Options)) (*acmpca.IssueCertificateOutput, error)
+	RevokeCertificate(ctx context.Context, params *acmpca.RevokeCertificateInput, optFns ...func(*acmpca.Options)) (*acmpca.RevokeCertificateOutput, error)
 }
 
 // PCAProvisioner contains logic for issuing PCA certificates
 type PCAProvisioner struct {
-	pcaClient        acmPCAClient
-	arn              string
-	signingAlgorithm *acmpcatypes.SigningAlgorithm
-	clock            func() time.Time
+	pcaClient           acmPCAClient
+	arn                 string
+	templateArn         string
+	allowedTemplateArns []string
+	apiPassthrough      *acmpcatypes.ApiPassthrough
+	signingAlgorithm    acmpcatypes.SigningAlgorithm
+	validity            validityOptions
+	issuancePolicy      *issuancePolicy
+	description         *caDescription
+	limiter             *RateLimiter
+	rateLimit           *api.RateLimit
+	clock               func() time.Time
 }
 
-func GetConfig(ctx context.Context, client client.Client, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
-	cfg, err := LoadConfig(ctx, client, spec)
+func GetConfig(ctx context.Context, client client.Client, name types.NamespacedName, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
+	cfg, err := LoadConfig(ctx, client, name, spec)
 
 	if err != nil {
 		return aws.Config{}, err
@@ -90,44 +118,54 @@ func GetConfig(ctx context.Context, client client.Client, spec *api.AWSPCAIssuer
 	return cfg, nil
 }
 
-func LoadConfig(ctx context.Context, client client.Client, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
+func LoadConfig(ctx context.Context, client client.Client, name types.NamespacedName, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
+	if spec.CredentialsRef != nil {
+		return loadSharedCredentialsConfig(ctx, client, name, spec)
+	}
+	return loadConfig(ctx, client, issuerSessionNameData(name), spec)
+}
+
+// loadConfig loads the config for the credentials configured in spec, which
+// belongs to the object described by session
+func loadConfig(ctx context.Context, client client.Client, session sessionNameData, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
+	if err := validateSecretNamespaces(session, IssuerSecrets(spec)); err != nil {
+		return aws.Config{}, err
+	}
+
 	var configOptions []func(*config.LoadOptions) error
-	if spec.Region != "" {
-		configOptions = append(configOptions, config.WithRegion(spec.Region))
+	if region := issuerRegion(spec); region != "" {
+		configOptions = append(configOptions, config.WithRegion(region))
 	}
+	configOptions = append(configOptions, endpointConfigOptions(spec)...)
 
 	if spec.SecretRef.Name != "" {
-		secretNamespaceName := types.NamespacedName{
-			Namespace: spec.SecretRef.Namespace,
-			Name:      spec.SecretRef.Name,
-		}
+		provider := newSecretCredentialsProvider(client, spec.SecretRef)
 
-		secret := new(core.Secret)
-		if err := client.Get(ctx, secretNamespaceName, secret); err != nil {
-			return aws.Config{}, fmt.Errorf("failed to retrieve secret: %v", err)
+		// Read the secret up front, so that missing keys are reported when
+		// the issuer is verified rather than when a certificate is signed
+		if _, err := provider.Retrieve(ctx); err != nil {
+			return aws.Config{}, err
 		}
 
-		key := "AWS_ACCESS_KEY_ID"
-		if spec.SecretRef.AccessKeyIDSelector.Key != "" {
-			key = spec.SecretRef.AccessKeyIDSelector.Key
-		}
-		accessKey, ok := secret.Data[key]
-		if !ok {
-			return aws.Config{}, ErrNoAccessKeyID
-		}
+		configOptions = append(configOptions, config.WithCredentialsProvider(
+			aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+				o.ExpiryWindow = credentialsExpiryWindow
+			})),
+		)
+	}
 
-		key = "AWS_SECRET_ACCESS_KEY"
-		if spec.SecretRef.SecretAccessKeySelector.Key != "" {
-			key = spec.SecretRef.SecretAccessKeySelector.Key
-		}
-		secretKey, ok := secret.Data[key]
-		if !ok {
-			return aws.Config{}, ErrNoSecretAccessKey
+	if spec.RolesAnywhere != nil {
+		creds := aws.NewCredentialsCache(newRolesAnywhereProvider(client, spec), func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = credentialsExpiryWindow
+		})
+
+		// Create the first session up front, so that a certificate that is
+		// not trusted is reported when the issuer is verified
+		if _, err := creds.Retrieve(ctx); err != nil {
+			return aws.Config{}, err
 		}
 
-		configOptions = append(configOptions, config.WithCredentialsProvider(
-			credentials.NewStaticCredentialsProvider(string(accessKey), string(secretKey), "")),
-		)
+		configOptions = append(configOptions, config.WithCredentialsProvider(creds))
 	}
 
 	cfg, err := config.LoadDefaultConfig(ctx, configOptions...)
@@ -136,11 +174,21 @@ func LoadConfig(ctx context.Context, client client.Client, spec *api.AWSPCAIssue
 	}
 
 	if spec.Role != "" {
-		stsService := sts.NewFromConfig(cfg)
-		creds := stscreds.NewAssumeRoleProvider(stsService, spec.Role)
+		creds, err := assumeRoleProvider(cfg, session, stsEndpoint(spec), spec.Role, spec.AssumeRoleOptions)
+		if err != nil {
+			return aws.Config{}, err
+		}
 		cfg.Credentials = aws.NewCredentialsCache(creds)
 	}
 
+	if len(spec.RoleChain) > 0 {
+		creds, err := assumeRoleChain(ctx, cfg, session, stsEndpoint(spec), spec.RoleChain)
+		if err != nil {
+			return aws.Config{}, err
+		}
+		cfg.Credentials = creds
+	}
+
 	return cfg, nil
 }
 
@@ -164,7 +212,18 @@ func GetProvisioner(ctx context.Context, client client.Client, name types.Namesp
 		return p, nil
 	}
 
-	config, err := GetConfig(ctx, client, spec)
+	config, err := GetConfig(ctx, client, name, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProvisioner(config, name, spec)
+}
+
+// NewProvisioner creates the provisioner of an issuer from the config loaded
+// by GetConfig, and stores it for GetProvisioner
+func NewProvisioner(config aws.Config, name types.NamespacedName, spec *api.AWSPCAIssuerSpec) (GenericProvisioner, error) {
+	policy, err := newIssuancePolicy(spec.IssuancePolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -172,8 +231,17 @@ func GetProvisioner(ctx context.Context, client client.Client, name types.Namesp
 	provisioner := &PCAProvisioner{
 		pcaClient: acmpca.NewFromConfig(config, acmpca.WithAPIOptions(
 			middleware.AddUserAgentKeyValue(injections.UserAgent, injections.PlugInVersion),
-		)),
-		arn: spec.Arn,
+		), withACMPCAEndpoint(acmPCAEndpoint(spec))),
+		arn:                 spec.Arn,
+		templateArn:         spec.TemplateArn,
+		allowedTemplateArns: spec.AllowedTemplateArns,
+		apiPassthrough:      toApiPassthrough(spec.ApiPassthrough),
+		signingAlgorithm:    acmpcatypes.SigningAlgorithm(spec.SigningAlgorithm),
+		validity:            toValidityOptions(spec),
+		issuancePolicy:      policy,
+		description:         &caDescription{},
+		limiter:             rateLimiter,
+		rateLimit:           spec.RateLimit,
 	}
 	collection.Store(name, provisioner)
 
@@ -181,45 +249,89 @@ func GetProvisioner(ctx context.Context, client client.Client, name types.Namesp
 }
 
 // idempotencyToken is limited to 64 ASCII characters, so make a fixed length hash.
+// The token covers everything that makes a request unique, so that a
+// CertificateRequest recreated with the same name but a new key or validity
+// is not answered with the certificate issued for its predecessor.
 // @see: https://docs.aws.amazon.com/AWSEC2/latest/APIReference/Run_Instance_Idempotency.html
 func idempotencyToken(cr *cmapi.CertificateRequest) string {
-	token := []byte(cr.Namespace + "/" + cr.Name)
-	fullHash := fmt.Sprintf("%x", sha256.Sum256(token))
+	duration := ""
+	if cr.Spec.Duration != nil {
+		duration = cr.Spec.Duration.Duration.String()
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{
+		[]byte(cr.Namespace + "/" + cr.Name),
+		[]byte(cr.UID),
+		[]byte(duration),
+		cr.Spec.Request,
+	} {
+		// Length prefix each part so that adjacent parts cannot be confused
+		fmt.Fprintf(hash, "%d:", len(part))
+		hash.Write(part)
+	}
+	fullHash := fmt.Sprintf("%x", hash.Sum(nil))
 	return fullHash[:36] // Truncate to 36 characters
 }
 
 // Sign takes a certificate request and signs it using PCA
 func (p *PCAProvisioner) Sign(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) error {
-	block, _ := pem.Decode(cr.Spec.Request)
-	if block == nil {
-		return fmt.Errorf("failed to decode CSR")
+	csr, err := parseCertificateRequest(cr)
+	if err != nil {
+		return err
 	}
-
-	validityExpiration := int64(p.now().Unix()) + DEFAULT_DURATION
-	if cr.Spec.Duration != nil {
-		validityExpiration = int64(p.now().Unix()) + int64(cr.Spec.Duration.Seconds())
+	duration, _, err := p.validity.duration(cr)
+	if err != nil {
+		return err
+	}
+	if err := p.issuancePolicy.check(cr, csr, duration); err != nil {
+		return err
 	}
 
-	tempArn := templateArn(p.arn, cr.Spec)
+	tempArn, err := p.selectTemplateArn(cr)
+	if err != nil {
+		return err
+	}
 
-	// Consider it a "retry" if we try to re-create a cert with the same name in the same namespace
+	// Consider it a "retry" if we try to re-create the same cert for the same CertificateRequest
 	token := idempotencyToken(cr)
 
-	err := getSigningAlgorithm(ctx, p)
+	ca, err := p.certificateAuthority(ctx)
+	if err != nil {
+		return err
+	}
+
+	signingAlgorithm, err := p.selectSigningAlgorithm(cr, ca)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSigningAlgorithm, err)
+	}
+
+	notBefore, notAfter, shortened, err := p.validity.validity(p.now(), cr, ca)
 	if err != nil {
 		return err
 	}
 
 	issueParams := acmpca.IssueCertificateInput{
 		CertificateAuthorityArn: aws.String(p.arn),
-		SigningAlgorithm:        *p.signingAlgorithm,
+		SigningAlgorithm:        signingAlgorithm,
 		TemplateArn:             aws.String(tempArn),
 		Csr:                     cr.Spec.Request,
 		Validity: &acmpcatypes.Validity{
 			Type:  acmpcatypes.ValidityPeriodTypeAbsolute,
-			Value: &validityExpiration,
+			Value: aws.Int64(notAfter.Unix()),
 		},
 		IdempotencyToken: aws.String(token),
+		ApiPassthrough:   p.apiPassthrough,
+	}
+	if p.validity.notBeforeBackdate > 0 {
+		issueParams.ValidityNotBefore = &acmpcatypes.Validity{
+			Type:  acmpcatypes.ValidityPeriodTypeAbsolute,
+			Value: aws.Int64(notBefore.Unix()),
+		}
+	}
+
+	if err := p.limiter.reserve(cr, operationIssueCertificate, p.arn, p.rateLimit); err != nil {
+		return err
 	}
 
 	issueOutput, err := p.pcaClient.IssueCertificate(ctx, &issueParams)
@@ -228,7 +340,10 @@ func (p *PCAProvisioner) Sign(ctx context.Context, cr *cmapi.CertificateRequest,
 		return err
 	}
 
-	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, "aws-privateca-issuer/certificate-arn", *issueOutput.CertificateArn)
+	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, CertificateArnAnnotation, *issueOutput.CertificateArn)
+	if shortened != "" {
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, ValidityShortenedAnnotation, shortened)
+	}
 
 	log.Info("Issued certificate with arn: " + *issueOutput.CertificateArn)
 
@@ -241,7 +356,15 @@ func (p *PCAProvisioner) Get(ctx context.Context, cr *cmapi.CertificateRequest,
 		CertificateAuthorityArn: aws.String(p.arn),
 	}
 
+	if err := p.limiter.reserve(cr, operationGetCertificate, p.arn, p.rateLimit); err != nil {
+		return nil, nil, err
+	}
+
 	getOutput, err := p.pcaClient.GetCertificate(ctx, &getParams)
+	var requestFailed *acmpcatypes.RequestFailedException
+	if errors.As(err, &requestFailed) {
+		return nil, nil, fmt.Errorf("%w: %v", ErrIssuanceFailed, err)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -259,24 +382,108 @@ func (p *PCAProvisioner) Get(ctx context.Context, cr *cmapi.CertificateRequest,
 	return certPem, rootCA, nil
 }
 
-func getSigningAlgorithm(ctx context.Context, p *PCAProvisioner) error {
-	if p.signingAlgorithm != nil {
-		return nil
+// Revoke revokes the certificate issued for a certificate request. The serial
+// is read from the issued certificate, which is fetched from PCA if the
+// certificate request does not hold it yet.
+func (p *PCAProvisioner) Revoke(ctx context.Context, cr *cmapi.CertificateRequest, certArn string, reason acmpcatypes.RevocationReason, log logr.Logger) error {
+	certPem := cr.Status.Certificate
+	if len(certPem) == 0 {
+		if err := p.limiter.reserve(cr, operationGetCertificate, p.arn, p.rateLimit); err != nil {
+			return err
+		}
+
+		getOutput, err := p.pcaClient.GetCertificate(ctx, &acmpca.GetCertificateInput{
+			CertificateArn:          aws.String(certArn),
+			CertificateAuthorityArn: aws.String(p.arn),
+		})
+		if err != nil {
+			return err
+		}
+		certPem = []byte(*getOutput.Certificate)
 	}
 
-	describeParams := acmpca.DescribeCertificateAuthorityInput{
-		CertificateAuthorityArn: aws.String(p.arn),
+	serial, err := certificateSerial(certPem)
+	if err != nil {
+		return err
+	}
+
+	if reason == "" {
+		reason = acmpcatypes.RevocationReasonUnspecified
 	}
-	describeOutput, err := p.pcaClient.DescribeCertificateAuthority(ctx, &describeParams)
 
+	if err := p.limiter.reserve(cr, operationRevokeCertificate, p.arn, p.rateLimit); err != nil {
+		return err
+	}
+
+	_, err = p.pcaClient.RevokeCertificate(ctx, &acmpca.RevokeCertificateInput{
+		CertificateAuthorityArn: aws.String(p.arn),
+		CertificateSerial:       aws.String(serial),
+		RevocationReason:        reason,
+	})
+
+	var alreadyRevoked *acmpcatypes.RequestAlreadyProcessedException
+	if errors.As(err, &alreadyRevoked) {
+		log.Info("Certificate was already revoked", "arn", certArn)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	p.signingAlgorithm = &describeOutput.CertificateAuthority.CertificateAuthorityConfiguration.SigningAlgorithm
+	log.Info("Revoked certificate with arn: "+certArn, "serial", serial, "reason", reason)
+
 	return nil
 }
 
+// certificateSerial returns the serial of the first certificate in a PEM
+// bundle as colon separated hex, the format expected by RevokeCertificate
+func certificateSerial(certPem []byte) (string, error) {
+	block, _ := pem.Decode(certPem)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("failed to decode certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse certificate: %v", err)
+	}
+
+	serialBytes := cert.SerialNumber.Bytes()
+	serial := make([]string, len(serialBytes))
+	for i, b := range serialBytes {
+		serial[i] = fmt.Sprintf("%02x", b)
+	}
+	return strings.Join(serial, ":"), nil
+}
+
+// DescribeCertificateAuthority returns the current state of the provisioner's
+// CA, and refreshes the description used for signing
+func (p *PCAProvisioner) DescribeCertificateAuthority(ctx context.Context) (*acmpcatypes.CertificateAuthority, error) {
+	ca, err := p.describeCertificateAuthority(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.description != nil {
+		p.description.mu.Lock()
+		p.description.ca = ca
+		p.description.describedAt = p.now()
+		p.description.mu.Unlock()
+	}
+	return ca, nil
+}
+
+func (p *PCAProvisioner) describeCertificateAuthority(ctx context.Context) (*acmpcatypes.CertificateAuthority, error) {
+	describeOutput, err := p.pcaClient.DescribeCertificateAuthority(ctx, &acmpca.DescribeCertificateAuthorityInput{
+		CertificateAuthorityArn: aws.String(p.arn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return describeOutput.CertificateAuthority, nil
+}
+
 func (p *PCAProvisioner) now() time.Time {
 	if p.clock != nil {
 		return p.clock()
@@ -285,34 +492,85 @@ func (p *PCAProvisioner) now() time.Time {
 	return time.Now()
 }
 
-func templateArn(caArn string, spec cmapi.CertificateRequestSpec) string {
-	arn := strings.SplitAfterN(caArn, ":", 3)
-	prefix := arn[0] + arn[1]
+// selectTemplateArn picks the template for a CertificateRequest. A template
+// requested through the annotation wins over the issuer's template, which in
+// turn wins over the template inferred from the request usages. Inferred
+// templates are switched to their APIPassthrough variant when the issuer
+// passes extensions or subject values through the API.
+func (p *PCAProvisioner) selectTemplateArn(cr *cmapi.CertificateRequest) (string, error) {
+	if requested, ok := cr.GetAnnotations()[TemplateArnAnnotation]; ok {
+		requestedArn := expandTemplateArn(p.arn, requested)
+		allowed := slices.ContainsFunc(p.allowedTemplateArns, func(allowedArn string) bool {
+			return expandTemplateArn(p.arn, allowedArn) == requestedArn
+		})
+		if !allowed {
+			return "", fmt.Errorf("%w: template %s is not allowed by the issuer", ErrIssuancePolicy, requested)
+		}
+		return requestedArn, nil
+	}
+
+	if p.templateArn != "" {
+		return expandTemplateArn(p.arn, p.templateArn), nil
+	}
+
+	if p.apiPassthrough != nil {
+		return apiPassthroughTemplateArn(templateArn(p.arn, cr.Spec)), nil
+	}
+
+	return templateArn(p.arn, cr.Spec), nil
+}
 
+// ValidateTemplateArn checks that a template is either a full template ARN or
+// a template name and version such as EndEntityCertificate/V1
+func ValidateTemplateArn(template string) error {
+	if !templateArnRegexp.MatchString(template) && !templateNameRegexp.MatchString(template) {
+		return fmt.Errorf("invalid template %q, expected a template ARN or a template name and version", template)
+	}
+	return nil
+}
+
+// expandTemplateArn turns a template name and version into a template ARN in
+// the same partition as the CA
+func expandTemplateArn(caArn string, template string) string {
+	if strings.HasPrefix(template, "arn:") {
+		return template
+	}
+	return caTemplateArn(caArn, template)
+}
+
+// caTemplateArn returns the ARN of a template in the partition of the CA. The
+// CA ARN is validated with the issuer, so a CA ARN that cannot be parsed falls
+// back to the aws partition.
+func caTemplateArn(caArn string, template string) string {
+	ca, _ := ParseCertificateAuthorityArn(caArn)
+	return ca.TemplateArn(template)
+}
+
+func templateArn(caArn string, spec cmapi.CertificateRequestSpec) string {
 	if spec.IsCA {
-		return prefix + "acm-pca:::template/SubordinateCACertificate_PathLen0/V1"
+		return caTemplateArn(caArn, "SubordinateCACertificate_PathLen0/V1")
 	}
 
 	if len(spec.Usages) == 1 {
 		switch spec.Usages[0] {
 		case cmapi.UsageCodeSigning:
-			return prefix + "acm-pca:::template/CodeSigningCertificate/V1"
+			return caTemplateArn(caArn, "CodeSigningCertificate/V1")
 		case cmapi.UsageClientAuth:
-			return prefix + "acm-pca:::template/EndEntityClientAuthCertificate/V1"
+			return caTemplateArn(caArn, "EndEntityClientAuthCertificate/V1")
 		case cmapi.UsageServerAuth:
-			return prefix + "acm-pca:::template/EndEntityServerAuthCertificate/V1"
+			return caTemplateArn(caArn, "EndEntityServerAuthCertificate/V1")
 		case cmapi.UsageOCSPSigning:
-			return prefix + "acm-pca:::template/OCSPSigningCertificate/V1"
+			return caTemplateArn(caArn, "OCSPSigningCertificate/V1")
 		}
 	} else if len(spec.Usages) == 2 {
 		clientServer := (spec.Usages[0] == cmapi.UsageClientAuth && spec.Usages[1] == cmapi.UsageServerAuth)
 		serverClient := (spec.Usages[0] == cmapi.UsageServerAuth && spec.Usages[1] == cmapi.UsageClientAuth)
 		if clientServer || serverClient {
-			return prefix + "acm-pca:::template/EndEntityCertificate/V1"
+			return caTemplateArn(caArn, "EndEntityCertificate/V1")
 		}
 	}
 
-	return prefix + "acm-pca:::template/BlankEndEntityCertificate_APICSRPassthrough/V1"
+	return caTemplateArn(caArn, "BlankEndEntityCertificate_APICSRPassthrough/V1")
 }
 
 func splitRootCACertificate(caCertChainPem []byte) ([]byte, []byte, error) {
@@ -177,12 +177,19 @@ func (m *errorACMPCAClient) IssueCertificate(_ context.Context, input *acmpca.Is
 	return nil, errors.New("Cannot issue certificate")
 }
 
+func (m *errorACMPCAClient) RevokeCertificate(_ context.Context, input *acmpca.RevokeCertificateInput, _ ...func(*acmpca.Options)) (*acmpca.RevokeCertificateOutput, error) {
+	return nil, errors.New("Cannot revoke certificate")
+}
+
 func (m *errorACMPCAClient) GetCertificate(_ context.Context, input *acmpca.GetCertificateInput, _ ...func(*acmpca.Options)) (*acmpca.GetCertificateOutput, error) {
 	return nil, errors.New("Cannot get certificate")
 }
 
 type workingACMPCAClient struct {
-	issueCertInput *acmpca.IssueCertificateInput
+	issueCertInput  *acmpca.IssueCertificateInput
+	revokeCertInput *acmpca.RevokeCertificateInput
+	revokeErr       error
+	getErr          error
 }
 
 func (m *workingACMPCAClient) DescribeCertificateAuthority(_ context.Context, input *acmpca.DescribeCertificateAuthorityInput, _ ...func(*acmpca.Options)) (*acmpca.DescribeCertificateAuthorityOutput, error) {
@@ -200,7 +207,15 @@ func (m *workingACMPCAClient) IssueCertificate(_ context.Context, input *acmpca.
 	return &acmpca.IssueCertificateOutput{CertificateArn: &certArn}, nil
 }
 
+func (m *workingACMPCAClient) RevokeCertificate(_ context.Context, input *acmpca.RevokeCertificateInput, _ ...func(*acmpca.Options)) (*acmpca.RevokeCertificateOutput, error) {
+	m.revokeCertInput = input
+	return &acmpca.RevokeCertificateOutput{}, m.revokeErr
+}
+
 func (m *workingACMPCAClient) GetCertificate(_ context.Context, input *acmpca.GetCertificateInput, _ ...func(*acmpca.Options)) (*acmpca.GetCertificateOutput, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
 	return &acmpca.GetCertificateOutput{Certificate: &cert, CertificateChain: &chain}, nil
 }
 
@@ -252,6 +267,23 @@ func TestProvisonerOperation(t *testing.T) {
 	assert.Equal(t, err, nil)
 }
 
+func TestNewProvisionerIsStored(t *testing.T) {
+	name := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+	issSpec := &issuerapi.AWSPCAIssuerSpec{
+		Region: "us-east-1",
+		Arn:    "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+	}
+
+	ClearProvisioners()
+	provisioner, err := NewProvisioner(aws.Config{Region: "us-east-1"}, name, issSpec)
+	require.NoError(t, err)
+
+	// The stored provisioner is returned without loading the config again
+	output, err := GetProvisioner(context.TODO(), nil, name, issSpec)
+	require.NoError(t, err)
+	assert.Same(t, provisioner, output)
+}
+
 func TestPCATemplateArn(t *testing.T) {
 	var (
 		arn     = "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012"
@@ -358,6 +390,96 @@ func TestPCATemplateArn(t *testing.T) {
 	}
 }
 
+func TestPCASelectTemplateArn(t *testing.T) {
+	type testCase struct {
+		provisioner   PCAProvisioner
+		annotations   map[string]string
+		expectedArn   string
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"inferred from usages": {
+			provisioner: PCAProvisioner{arn: arn},
+			expectedArn: "arn:aws:acm-pca:::template/EndEntityServerAuthCertificate/V1",
+		},
+		"issuer template name": {
+			provisioner: PCAProvisioner{arn: arn, templateArn: "SubordinateCACertificate_PathLen1/V1"},
+			expectedArn: "arn:aws:acm-pca:::template/SubordinateCACertificate_PathLen1/V1",
+		},
+		"issuer template arn": {
+			provisioner: PCAProvisioner{arn: arn, templateArn: "arn:aws:acm-pca:::template/EndEntityCertificate_APIPassthrough/V1"},
+			expectedArn: "arn:aws:acm-pca:::template/EndEntityCertificate_APIPassthrough/V1",
+		},
+		"annotation allowed": {
+			provisioner: PCAProvisioner{
+				arn:                 arn,
+				templateArn:         "EndEntityCertificate/V1",
+				allowedTemplateArns: []string{"arn:aws:acm-pca:::template/RootCACertificate/V1"},
+			},
+			annotations: map[string]string{TemplateArnAnnotation: "RootCACertificate/V1"},
+			expectedArn: "arn:aws:acm-pca:::template/RootCACertificate/V1",
+		},
+		"annotation not allowed": {
+			provisioner: PCAProvisioner{
+				arn:                 arn,
+				allowedTemplateArns: []string{"RootCACertificate/V1"},
+			},
+			annotations:   map[string]string{TemplateArnAnnotation: "SubordinateCACertificate_PathLen3/V1"},
+			expectFailure: true,
+		},
+		"annotation without allowed templates": {
+			provisioner:   PCAProvisioner{arn: arn},
+			annotations:   map[string]string{TemplateArnAnnotation: "RootCACertificate/V1"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := &cmapi.CertificateRequest{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tc.annotations,
+				},
+				Spec: cmapi.CertificateRequestSpec{
+					Usages: []cmapi.KeyUsage{cmapi.UsageServerAuth},
+				},
+			}
+
+			response, err := tc.provisioner.selectTemplateArn(cr)
+			if tc.expectFailure {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedArn, response)
+		})
+	}
+}
+
+func TestValidateTemplateArn(t *testing.T) {
+	tests := map[string]bool{
+		"EndEntityCertificate/V1":                                           true,
+		"SubordinateCACertificate_PathLen1/V1":                              true,
+		"arn:aws:acm-pca:::template/EndEntityCertificate_APIPassthrough/V1": true,
+		"arn:aws-us-gov:acm-pca:::template/RootCACertificate/V1":            true,
+		"EndEntityCertificate":                                              false,
+		"arn:aws:acm-pca:us-east-1::template/EndEntityCertificate/V1":       false,
+		"": false,
+	}
+
+	for template, valid := range tests {
+		t.Run(template, func(t *testing.T) {
+			err := ValidateTemplateArn(template)
+			if valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
 func TestIdempotencyToken(t *testing.T) {
 	var (
 		idempotencyTokenMaxLength = 36
@@ -376,7 +498,7 @@ func TestIdempotencyToken(t *testing.T) {
 					Namespace: "fake-namespace",
 				},
 			},
-			expected: "63e69830270b95081942a3d85034fdc97bb9", // Truncated SHA-256 hash
+			expected: "8ecce726e77a19e601bb35b8c19dece870ac", // Truncated SHA-256 hash
 		},
 	}
 
@@ -389,6 +511,95 @@ func TestIdempotencyToken(t *testing.T) {
 	}
 }
 
+// idempotentACMPCAClient mimics PCA by returning the same certificate for
+// requests that reuse an idempotency token
+type idempotentACMPCAClient struct {
+	workingACMPCAClient
+	issued map[string]string
+	calls  int
+}
+
+func (m *idempotentACMPCAClient) IssueCertificate(_ context.Context, input *acmpca.IssueCertificateInput, _ ...func(*acmpca.Options)) (*acmpca.IssueCertificateOutput, error) {
+	if certArn, ok := m.issued[*input.IdempotencyToken]; ok {
+		return &acmpca.IssueCertificateOutput{CertificateArn: aws.String(certArn)}, nil
+	}
+	m.calls++
+	certArn := fmt.Sprintf("arn:aws:acm-pca:us-east-1:account:certificate-authority/ca/certificate/%d", m.calls)
+	m.issued[*input.IdempotencyToken] = certArn
+	return &acmpca.IssueCertificateOutput{CertificateArn: aws.String(certArn)}, nil
+}
+
+func TestIdempotencyTokenCoversRequest(t *testing.T) {
+	base := func() *cmapi.CertificateRequest {
+		return &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "fake-name",
+				Namespace: "fake-namespace",
+				UID:       "uid-1",
+			},
+			Spec: cmapi.CertificateRequestSpec{
+				Request:  []byte("csr-1"),
+				Duration: ptrDuration(metav1.Duration{Duration: time.Hour}),
+			},
+		}
+	}
+
+	tests := map[string]func(cr *cmapi.CertificateRequest){
+		"uid": func(cr *cmapi.CertificateRequest) { cr.UID = "uid-2" },
+		"csr": func(cr *cmapi.CertificateRequest) { cr.Spec.Request = []byte("csr-2") },
+		"duration": func(cr *cmapi.CertificateRequest) {
+			cr.Spec.Duration = ptrDuration(metav1.Duration{Duration: 2 * time.Hour})
+		},
+		"name": func(cr *cmapi.CertificateRequest) { cr.Name = "other-name" },
+	}
+
+	assert.Equal(t, idempotencyToken(base()), idempotencyToken(base()), "token is stable for the same request")
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := base()
+			mutate(cr)
+			assert.NotEqual(t, idempotencyToken(base()), idempotencyToken(cr))
+		})
+	}
+}
+
+func TestPCASignRekeyedRequest(t *testing.T) {
+	client := &idempotentACMPCAClient{issued: map[string]string{}}
+	provisioner := PCAProvisioner{arn: arn, pcaClient: client}
+
+	newRequest := func(uid types.UID) *cmapi.CertificateRequest {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		csrBytes, _ := x509.CreateCertificateRequest(rand.Reader, &template, key)
+		return &cmapi.CertificateRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cr1",
+				Namespace: "ns1",
+				UID:       uid,
+			},
+			Spec: cmapi.CertificateRequestSpec{
+				Request: pem.EncodeToMemory(&pem.Block{
+					Bytes: csrBytes,
+					Type:  "CERTIFICATE REQUEST",
+				}),
+			},
+		}
+	}
+
+	original := newRequest("uid-1")
+	require.NoError(t, provisioner.Sign(context.TODO(), original, logr.Discard()))
+
+	retried := original.DeepCopy()
+	retried.Annotations = nil
+	require.NoError(t, provisioner.Sign(context.TODO(), retried, logr.Discard()))
+	assert.Equal(t, 1, client.calls, "retrying the same request reuses the issued certificate")
+	assert.Equal(t, original.Annotations["aws-privateca-issuer/certificate-arn"], retried.Annotations["aws-privateca-issuer/certificate-arn"])
+
+	rekeyed := newRequest("uid-2")
+	require.NoError(t, provisioner.Sign(context.TODO(), rekeyed, logr.Discard()))
+	assert.Equal(t, 2, client.calls, "a recreated request with a new key issues a new certificate")
+	assert.NotEqual(t, original.Annotations["aws-privateca-issuer/certificate-arn"], rekeyed.Annotations["aws-privateca-issuer/certificate-arn"])
+}
+
 func TestPCAGetConfig(t *testing.T) {
 	type testCase struct {
 		name          types.NamespacedName
@@ -701,7 +912,7 @@ func TestPCAGetConfig(t *testing.T) {
 			iss := new(issuerapi.AWSPCAIssuer)
 			require.NoError(t, fakeClient.Get(ctx, tc.name, iss))
 
-			config, err := GetConfig(ctx, fakeClient, iss.GetSpec())
+			config, err := GetConfig(ctx, fakeClient, tc.name, iss.GetSpec())
 
 			if tc.expectFailure && err == nil {
 				assert.Fail(t, "Expected an error but got none")
@@ -722,10 +933,11 @@ func TestPCAGetConfig(t *testing.T) {
 
 func TestPCAGet(t *testing.T) {
 	type testCase struct {
-		provisioner   PCAProvisioner
-		expectFailure bool
-		expectedChain string
-		expectedCert  string
+		provisioner     PCAProvisioner
+		expectFailure   bool
+		expectPermanent bool
+		expectedChain   string
+		expectedCert    string
 	}
 
 	tests := map[string]testCase{
@@ -739,6 +951,11 @@ func TestPCAGet(t *testing.T) {
 			provisioner:   PCAProvisioner{arn: arn, pcaClient: &errorACMPCAClient{}},
 			expectFailure: true,
 		},
+		"failure-request-failed": {
+			provisioner:     PCAProvisioner{arn: arn, pcaClient: &workingACMPCAClient{getErr: &acmpcatypes.RequestFailedException{}}},
+			expectFailure:   true,
+			expectPermanent: true,
+		},
 	}
 
 	for name, tc := range tests {
@@ -761,6 +978,10 @@ func TestPCAGet(t *testing.T) {
 				fmt.Print(err.Error())
 				assert.Fail(t, "Expected an error but received none")
 			}
+			if tc.expectPermanent {
+				assert.ErrorIs(t, err, ErrIssuanceFailed)
+				assert.True(t, IsPermanentError(err), "expected a permanent error")
+			}
 
 			if tc.expectedChain != "" && tc.expectedCert != "" {
 				assert.Equal(t, []byte(tc.expectedCert), leaf)
@@ -818,41 +1039,91 @@ func TestPCASign(t *testing.T) {
 }
 
 func TestPCASignValidity(t *testing.T) {
-	now := time.Now()
-	client := &workingACMPCAClient{}
-	provisioner := PCAProvisioner{arn: arn, pcaClient: client}
-	provisioner.clock = func() time.Time { return now }
+	now := time.Unix(1700000000, 0)
+	caNotBefore := now.Add(-24 * time.Hour)
+	caNotAfter := now.Add(90 * 24 * time.Hour)
+	ca := &acmpcatypes.CertificateAuthority{
+		CertificateAuthorityConfiguration: &acmpcatypes.CertificateAuthorityConfiguration{
+			SigningAlgorithm: acmpcatypes.SigningAlgorithmSha256withecdsa,
+		},
+		NotBefore: &caNotBefore,
+		NotAfter:  &caNotAfter,
+	}
+
 	type testCase struct {
-		duration      *metav1.Duration
-		expectedInput *acmpca.IssueCertificateInput
+		validity          validityOptions
+		duration          *metav1.Duration
+		expectedNotBefore *int64
+		expectedNotAfter  int64
+		expectedShortened string
+		expectedError     error
 	}
 
 	tests := map[string]testCase{
 		"default": {
-			duration: nil,
-			expectedInput: &acmpca.IssueCertificateInput{
-				CertificateAuthorityArn: aws.String(arn),
-				Validity: &acmpcatypes.Validity{
-					Type:  acmpcatypes.ValidityPeriodTypeAbsolute,
-					Value: ptrInt(int64(now.Unix()) + DEFAULT_DURATION),
-				},
-			},
+			expectedNotAfter: now.Add(DefaultDuration).Unix(),
+		},
+		"default over maximum": {
+			validity:         validityOptions{maxDuration: 24 * time.Hour},
+			expectedNotAfter: now.Add(24 * time.Hour).Unix(),
+		},
+		"zero duration": {
+			duration:      ptrDuration(metav1.Duration{}),
+			expectedError: ErrInvalidDuration,
 		},
 		"duration specified": {
-			duration: ptrDuration(metav1.Duration{Duration: 3 * time.Hour}),
-			expectedInput: &acmpca.IssueCertificateInput{
-				CertificateAuthorityArn: aws.String(arn),
-				Validity: &acmpcatypes.Validity{
-					Type:  acmpcatypes.ValidityPeriodTypeAbsolute,
-					Value: ptrInt(int64(now.Unix()) + int64(3*time.Hour.Seconds())),
-				},
-			},
+			duration:         ptrDuration(metav1.Duration{Duration: 3 * time.Hour}),
+			expectedNotAfter: now.Add(3 * time.Hour).Unix(),
+		},
+		"issuer default duration": {
+			validity:         validityOptions{defaultDuration: 7 * 24 * time.Hour},
+			expectedNotAfter: now.Add(7 * 24 * time.Hour).Unix(),
+		},
+		"duration over maximum": {
+			validity:          validityOptions{maxDuration: 24 * time.Hour},
+			duration:          ptrDuration(metav1.Duration{Duration: 48 * time.Hour}),
+			expectedNotAfter:  now.Add(24 * time.Hour).Unix(),
+			expectedShortened: "the requested duration 48h0m0s was shortened to the issuer's maxDuration of 24h0m0s",
+		},
+		"issuer default duration over maximum": {
+			validity:         validityOptions{defaultDuration: 48 * time.Hour, maxDuration: 24 * time.Hour},
+			expectedNotAfter: now.Add(24 * time.Hour).Unix(),
+		},
+		"backdated": {
+			validity:          validityOptions{notBeforeBackdate: 5 * time.Minute},
+			duration:          ptrDuration(metav1.Duration{Duration: time.Hour}),
+			expectedNotBefore: ptrInt(now.Add(-5 * time.Minute).Unix()),
+			expectedNotAfter:  now.Add(time.Hour).Unix(),
+		},
+		"backdated before the CA": {
+			validity:          validityOptions{notBeforeBackdate: 48 * time.Hour},
+			duration:          ptrDuration(metav1.Duration{Duration: time.Hour}),
+			expectedNotBefore: ptrInt(caNotBefore.Unix()),
+			expectedNotAfter:  now.Add(time.Hour).Unix(),
+		},
+		"outlives the CA": {
+			duration:      ptrDuration(metav1.Duration{Duration: 365 * 24 * time.Hour}),
+			expectedError: ErrValidityExceedsCA,
+		},
+		"outlives the CA with Fail policy": {
+			validity:      validityOptions{caExpiryPolicy: issuerapi.CAExpiryPolicyFail},
+			duration:      ptrDuration(metav1.Duration{Duration: 365 * 24 * time.Hour}),
+			expectedError: ErrValidityExceedsCA,
+		},
+		"outlives the CA with Clamp policy": {
+			validity:          validityOptions{caExpiryPolicy: issuerapi.CAExpiryPolicyClamp},
+			duration:          ptrDuration(metav1.Duration{Duration: 365 * 24 * time.Hour}),
+			expectedNotAfter:  caNotAfter.Unix(),
+			expectedShortened: "the validity was shortened to end when the certificate authority expires at " + caNotAfter.UTC().Format(time.RFC3339),
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			client.issueCertInput = nil
+			client := &workingACMPCAClient{}
+			provisioner := PCAProvisioner{arn: arn, pcaClient: client, validity: tc.validity, description: &caDescription{ca: ca, describedAt: now}}
+			provisioner.clock = func() time.Time { return now }
+
 			key, _ := rsa.GenerateKey(rand.Reader, 2048)
 			csrBytes, _ := x509.CreateCertificateRequest(rand.Reader, &template, key)
 
@@ -866,20 +1137,86 @@ func TestPCASignValidity(t *testing.T) {
 				},
 			}
 
-			_ = provisioner.Sign(context.TODO(), cr, logr.Discard())
+			err := provisioner.Sign(context.TODO(), cr, logr.Discard())
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, client.issueCertInput)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedShortened, cr.GetAnnotations()[ValidityShortenedAnnotation])
+
 			got := client.issueCertInput
-			if got == nil {
-				assert.Fail(t, "Expected certificate input, got none")
+			require.NotNil(t, got)
+			assert.Equal(t, arn, *got.CertificateAuthorityArn)
+			assert.Equal(t, acmpcatypes.ValidityPeriodTypeAbsolute, got.Validity.Type)
+			assert.Equal(t, tc.expectedNotAfter, *got.Validity.Value)
+			if tc.expectedNotBefore == nil {
+				assert.Nil(t, got.ValidityNotBefore)
 			} else {
-				assert.Equal(t, *got.CertificateAuthorityArn, *tc.expectedInput.CertificateAuthorityArn, name)
-				assert.Equal(t, got.Validity.Type, tc.expectedInput.Validity.Type, name)
-				assert.Equal(t, *got.Validity.Value, *tc.expectedInput.Validity.Value, name)
+				require.NotNil(t, got.ValidityNotBefore)
+				assert.Equal(t, acmpcatypes.ValidityPeriodTypeAbsolute, got.ValidityNotBefore.Type)
+				assert.Equal(t, *tc.expectedNotBefore, *got.ValidityNotBefore.Value)
 			}
-
 		})
 	}
 }
 
+func TestPCARevoke(t *testing.T) {
+	type testCase struct {
+		client         *workingACMPCAClient
+		certificate    []byte
+		reason         acmpcatypes.RevocationReason
+		expectedReason acmpcatypes.RevocationReason
+		expectFailure  bool
+	}
+
+	tests := map[string]testCase{
+		"serial from issued certificate": {
+			client:         &workingACMPCAClient{},
+			certificate:    []byte(cert + "\n" + intermediate),
+			reason:         acmpcatypes.RevocationReasonKeyCompromise,
+			expectedReason: acmpcatypes.RevocationReasonKeyCompromise,
+		},
+		"serial from PCA": {
+			client:         &workingACMPCAClient{},
+			expectedReason: acmpcatypes.RevocationReasonUnspecified,
+		},
+		"already revoked": {
+			client:         &workingACMPCAClient{revokeErr: &acmpcatypes.RequestAlreadyProcessedException{}},
+			certificate:    []byte(cert),
+			expectedReason: acmpcatypes.RevocationReasonUnspecified,
+		},
+		"revoke failure": {
+			client:        &workingACMPCAClient{revokeErr: errors.New("Cannot revoke certificate")},
+			certificate:   []byte(cert),
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			provisioner := PCAProvisioner{arn: arn, pcaClient: tc.client}
+			cr := &cmapi.CertificateRequest{
+				Status: cmapi.CertificateRequestStatus{
+					Certificate: tc.certificate,
+				},
+			}
+
+			err := provisioner.Revoke(context.TODO(), cr, certArn, tc.reason, logr.Discard())
+			if tc.expectFailure {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, tc.client.revokeCertInput)
+			assert.Equal(t, arn, *tc.client.revokeCertInput.CertificateAuthorityArn)
+			assert.Equal(t, "12:34", *tc.client.revokeCertInput.CertificateSerial)
+			assert.Equal(t, tc.expectedReason, tc.client.revokeCertInput.RevocationReason)
+		})
+	}
+}
 
 type RoundTripFunc func(req *http.Request) (*http.Response, error)
 
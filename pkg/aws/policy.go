@@ -0,0 +1,323 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+)
+
+var (
+	// ErrInvalidRequest is returned when the CSR of a CertificateRequest
+	// cannot be parsed or is not signed by its key
+	ErrInvalidRequest = errors.New("invalid certificate signing request")
+	// ErrIssuancePolicy is returned when a CertificateRequest violates the
+	// issuance policy of its issuer
+	ErrIssuancePolicy = errors.New("certificate request violates the issuance policy of the issuer")
+)
+
+// Key algorithms of an IssuancePolicy
+const (
+	keyAlgorithmRSA     = "RSA"
+	keyAlgorithmECDSA   = "ECDSA"
+	keyAlgorithmEd25519 = "Ed25519"
+)
+
+// issuancePolicy is an api.IssuancePolicy with its patterns compiled
+type issuancePolicy struct {
+	keyAlgorithms  []api.KeyAlgorithmPolicy
+	dnsNames       []*regexp.Regexp
+	domainSuffixes []string
+	ipRanges       []*net.IPNet
+	uris           []*regexp.Regexp
+	usages         []cmapi.KeyUsage
+	allowCA        bool
+	maxDuration    time.Duration
+}
+
+// ValidateIssuancePolicy checks that the patterns and ranges of an issuance
+// policy can be parsed
+func ValidateIssuancePolicy(policy *api.IssuancePolicy) error {
+	_, err := newIssuancePolicy(policy)
+	return err
+}
+
+func newIssuancePolicy(policy *api.IssuancePolicy) (*issuancePolicy, error) {
+	if policy == nil {
+		return nil, nil
+	}
+
+	p := &issuancePolicy{
+		keyAlgorithms:  policy.AllowedKeyAlgorithms,
+		domainSuffixes: policy.AllowedDomainSuffixes,
+		usages:         policy.AllowedUsages,
+		allowCA:        policy.AllowCA,
+	}
+	for _, algorithm := range policy.AllowedKeyAlgorithms {
+		switch algorithm.Algorithm {
+		case keyAlgorithmRSA, keyAlgorithmECDSA, keyAlgorithmEd25519:
+		default:
+			return nil, fmt.Errorf("unknown key algorithm %q", algorithm.Algorithm)
+		}
+		if algorithm.MinSize < 0 {
+			return nil, fmt.Errorf("minimum size %d of key algorithm %s is negative", algorithm.MinSize, algorithm.Algorithm)
+		}
+	}
+	for _, pattern := range policy.AllowedDNSNames {
+		re, err := compileFullMatch(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DNS name pattern %q: %v", pattern, err)
+		}
+		p.dnsNames = append(p.dnsNames, re)
+	}
+	for _, suffix := range policy.AllowedDomainSuffixes {
+		if strings.Trim(suffix, ".") == "" {
+			return nil, fmt.Errorf("invalid domain suffix %q", suffix)
+		}
+	}
+	for _, cidr := range policy.AllowedIPRanges {
+		_, ipRange, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP range %q: %v", cidr, err)
+		}
+		p.ipRanges = append(p.ipRanges, ipRange)
+	}
+	for _, pattern := range policy.AllowedURIs {
+		re, err := compileFullMatch(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URI pattern %q: %v", pattern, err)
+		}
+		p.uris = append(p.uris, re)
+	}
+	if policy.MaxDuration != nil {
+		if policy.MaxDuration.Duration < 0 {
+			return nil, fmt.Errorf("maxDuration %s is negative", policy.MaxDuration.Duration)
+		}
+		p.maxDuration = policy.MaxDuration.Duration
+	}
+	return p, nil
+}
+
+func compileFullMatch(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// parseCertificateRequest decodes and parses the CSR of a CertificateRequest,
+// and checks that it is signed by the key it contains
+func parseCertificateRequest(cr *cmapi.CertificateRequest) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(cr.Spec.Request)
+	if block == nil {
+		return nil, fmt.Errorf("%w: failed to decode CSR", ErrInvalidRequest)
+	}
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequest, err)
+	}
+	return csr, nil
+}
+
+// check returns the first violation of the policy by a CertificateRequest,
+// its parsed CSR and the duration of the certificate that would be issued
+// for it. A nil policy allows everything.
+func (p *issuancePolicy) check(cr *cmapi.CertificateRequest, csr *x509.CertificateRequest, duration time.Duration) error {
+	if p == nil {
+		return nil
+	}
+
+	if err := p.checkKey(csr.PublicKey); err != nil {
+		return err
+	}
+
+	if cn := csr.Subject.CommonName; cn != "" {
+		if ip := net.ParseIP(cn); ip != nil {
+			if !p.allowsIP(ip) {
+				return fmt.Errorf("%w: common name %s is not in an allowed IP range", ErrIssuancePolicy, cn)
+			}
+		} else if !p.allowsDNSName(cn) {
+			return fmt.Errorf("%w: common name %q is not an allowed DNS name", ErrIssuancePolicy, cn)
+		}
+	}
+	for _, name := range csr.DNSNames {
+		if !p.allowsDNSName(name) {
+			return fmt.Errorf("%w: DNS name %q is not allowed", ErrIssuancePolicy, name)
+		}
+	}
+	for _, ip := range csr.IPAddresses {
+		if !p.allowsIP(ip) {
+			return fmt.Errorf("%w: IP address %s is not in an allowed IP range", ErrIssuancePolicy, ip)
+		}
+	}
+	for _, uri := range csr.URIs {
+		if !matchesAny(p.uris, uri.String()) {
+			return fmt.Errorf("%w: URI %q is not allowed", ErrIssuancePolicy, uri)
+		}
+	}
+
+	if len(p.usages) > 0 {
+		usages, err := requestedUsages(cr, csr)
+		if err != nil {
+			return err
+		}
+		for _, usage := range usages {
+			if !p.allowsUsage(usage) {
+				return fmt.Errorf("%w: usage %q is not allowed", ErrIssuancePolicy, usage)
+			}
+		}
+	}
+
+	if cr.Spec.IsCA && !p.allowCA {
+		return fmt.Errorf("%w: CA certificates are not allowed", ErrIssuancePolicy)
+	}
+
+	if p.maxDuration > 0 && duration > p.maxDuration {
+		return fmt.Errorf("%w: duration %s exceeds the maximum of %s", ErrIssuancePolicy, duration, p.maxDuration)
+	}
+
+	return nil
+}
+
+func (p *issuancePolicy) checkKey(key any) error {
+	if len(p.keyAlgorithms) == 0 {
+		return nil
+	}
+
+	var algorithm string
+	var size int
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		algorithm, size = keyAlgorithmRSA, key.N.BitLen()
+	case *ecdsa.PublicKey:
+		algorithm, size = keyAlgorithmECDSA, key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		algorithm, size = keyAlgorithmEd25519, 256
+	default:
+		return fmt.Errorf("%w: key of type %T is not allowed", ErrIssuancePolicy, key)
+	}
+
+	for _, allowed := range p.keyAlgorithms {
+		if allowed.Algorithm == algorithm && size >= allowed.MinSize {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d bit %s key is not allowed", ErrIssuancePolicy, size, algorithm)
+}
+
+// requestedUsages returns the usages of a CertificateRequest, or the default
+// usages of cert-manager if it has none, together with the usages requested
+// by the key usage and extended key usage extensions of its CSR. Extended key
+// usages without a cert-manager name are returned as their OID.
+func requestedUsages(cr *cmapi.CertificateRequest, csr *x509.CertificateRequest) ([]cmapi.KeyUsage, error) {
+	usages := cr.Spec.Usages
+	if len(usages) == 0 {
+		usages = []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment}
+	}
+	for _, ext := range csr.Extensions {
+		switch {
+		case ext.Id.Equal(pki.OIDExtensionKeyUsage):
+			keyUsage, err := pki.UnmarshalKeyUsage(ext.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid key usage extension: %v", ErrInvalidRequest, err)
+			}
+			usages = append(usages, cmutil.KeyUsageStrings(keyUsage)...)
+		case ext.Id.Equal(pki.OIDExtensionExtendedKeyUsage):
+			extKeyUsages, unknown, err := pki.UnmarshalExtKeyUsage(ext.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid extended key usage extension: %v", ErrInvalidRequest, err)
+			}
+			usages = append(usages, cmutil.ExtKeyUsageStrings(extKeyUsages)...)
+			for _, oid := range unknown {
+				usages = append(usages, cmapi.KeyUsage(oid.String()))
+			}
+		}
+	}
+	return usages, nil
+}
+
+// allowsUsage reports whether a usage, or a usage with the same meaning such
+// as signing for digital signature, is allowed
+func (p *issuancePolicy) allowsUsage(usage cmapi.KeyUsage) bool {
+	keyUsage, isKeyUsage := cmutil.KeyUsageType(usage)
+	extKeyUsage, isExtKeyUsage := cmutil.ExtKeyUsageType(usage)
+	return slices.ContainsFunc(p.usages, func(allowed cmapi.KeyUsage) bool {
+		if allowed == usage {
+			return true
+		}
+		if u, ok := cmutil.KeyUsageType(allowed); ok && isKeyUsage && u == keyUsage {
+			return true
+		}
+		u, ok := cmutil.ExtKeyUsageType(allowed)
+		return ok && isExtKeyUsage && u == extKeyUsage
+	})
+}
+
+// allowsDNSName reports whether a DNS name matches one of the patterns or is
+// a subdomain of one of the suffixes. Any name is allowed when neither are set.
+func (p *issuancePolicy) allowsDNSName(name string) bool {
+	if len(p.dnsNames) == 0 && len(p.domainSuffixes) == 0 {
+		return true
+	}
+	if slices.ContainsFunc(p.dnsNames, func(re *regexp.Regexp) bool { return re.MatchString(name) }) {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, suffix := range p.domainSuffixes {
+		if strings.HasSuffix(name, "."+strings.ToLower(strings.Trim(suffix, "."))) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsIP reports whether an IP address is in one of the ranges. Any address
+// is allowed when no ranges are set.
+func (p *issuancePolicy) allowsIP(ip net.IP) bool {
+	if len(p.ipRanges) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(p.ipRanges, func(ipRange *net.IPNet) bool {
+		return ipRange.Contains(ip)
+	})
+}
+
+// matchesAny reports whether a value matches one of the patterns. Any value
+// matches when no patterns are set.
+func matchesAny(patterns []*regexp.Regexp, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(patterns, func(re *regexp.Regexp) bool {
+		return re.MatchString(value)
+	})
+}
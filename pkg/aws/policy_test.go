@@ -0,0 +1,332 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/cert-manager/cert-manager/pkg/util/pki"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func policyTestCSR(t *testing.T, key crypto.Signer, csrTemplate x509.CertificateRequest) []byte {
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &csrTemplate, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Bytes: csrBytes, Type: "CERTIFICATE REQUEST"})
+}
+
+func TestIssuancePolicy(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	keyUsage := func(usage x509.KeyUsage) []pkix.Extension {
+		ext, err := pki.MarshalKeyUsage(usage)
+		require.NoError(t, err)
+		return []pkix.Extension{ext}
+	}
+	extKeyUsage := func(usages []x509.ExtKeyUsage, unknown ...asn1.ObjectIdentifier) []pkix.Extension {
+		ext, err := pki.MarshalExtKeyUsage(usages, unknown)
+		require.NoError(t, err)
+		return []pkix.Extension{ext}
+	}
+
+	type testCase struct {
+		policy          issuerapi.IssuancePolicy
+		defaultDuration time.Duration
+		key             crypto.Signer
+		csr             x509.CertificateRequest
+		spec            cmapi.CertificateRequestSpec
+		expectFailure   bool
+	}
+
+	tests := map[string]testCase{
+		"empty-policy": {
+			csr:  x509.CertificateRequest{DNSNames: []string{"anything.example.org"}},
+			spec: cmapi.CertificateRequestSpec{Usages: []cmapi.KeyUsage{cmapi.UsageCertSign}},
+		},
+		"rsa-key-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{{Algorithm: "RSA", MinSize: 2048}}},
+		},
+		"rsa-key-too-small": {
+			policy:        issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{{Algorithm: "RSA", MinSize: 3072}}},
+			expectFailure: true,
+		},
+		"ecdsa-key-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{
+				{Algorithm: "RSA", MinSize: 3072},
+				{Algorithm: "ECDSA", MinSize: 256},
+			}},
+			key: ecKey,
+		},
+		"ecdsa-key-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{{Algorithm: "RSA"}}},
+			key:           ecKey,
+			expectFailure: true,
+		},
+		"ed25519-key-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{{Algorithm: "ECDSA"}}},
+			key:           edKey,
+			expectFailure: true,
+		},
+		"dns-name-matches-pattern": {
+			policy: issuerapi.IssuancePolicy{AllowedDNSNames: []string{`[a-z]+\.svc\.cluster\.local`}},
+			csr:    x509.CertificateRequest{DNSNames: []string{"web.svc.cluster.local"}},
+		},
+		"dns-name-partially-matches-pattern": {
+			policy:        issuerapi.IssuancePolicy{AllowedDNSNames: []string{`[a-z]+\.svc\.cluster\.local`}},
+			csr:           x509.CertificateRequest{DNSNames: []string{"web.svc.cluster.local.example.org"}},
+			expectFailure: true,
+		},
+		"dns-name-with-allowed-suffix": {
+			policy: issuerapi.IssuancePolicy{
+				AllowedDNSNames:       []string{`[a-z]+\.svc\.cluster\.local`},
+				AllowedDomainSuffixes: []string{"example.com"},
+			},
+			csr: x509.CertificateRequest{DNSNames: []string{"web.svc.cluster.local", "www.Example.com"}},
+		},
+		"dns-name-is-suffix": {
+			policy:        issuerapi.IssuancePolicy{AllowedDomainSuffixes: []string{"example.com"}},
+			csr:           x509.CertificateRequest{DNSNames: []string{"example.com"}},
+			expectFailure: true,
+		},
+		"dns-name-shares-suffix": {
+			policy:        issuerapi.IssuancePolicy{AllowedDomainSuffixes: []string{"example.com"}},
+			csr:           x509.CertificateRequest{DNSNames: []string{"badexample.com"}},
+			expectFailure: true,
+		},
+		"common-name-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedDomainSuffixes: []string{"example.com"}},
+			csr:           x509.CertificateRequest{Subject: pkix.Name{CommonName: "www.example.org"}, DNSNames: []string{"www.example.com"}},
+			expectFailure: true,
+		},
+		"ip-address-in-range": {
+			policy: issuerapi.IssuancePolicy{AllowedIPRanges: []string{"10.0.0.0/8"}},
+			csr:    x509.CertificateRequest{Subject: pkix.Name{CommonName: "10.1.2.3"}, IPAddresses: []net.IP{net.ParseIP("10.1.2.3")}},
+		},
+		"ip-address-out-of-range": {
+			policy:        issuerapi.IssuancePolicy{AllowedIPRanges: []string{"10.0.0.0/8"}},
+			csr:           x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("192.168.1.1")}},
+			expectFailure: true,
+		},
+		"uri-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedURIs: []string{`spiffe://cluster\.local/ns/[a-z-]+/sa/[a-z-]+`}},
+			csr:    x509.CertificateRequest{URIs: []*url.URL{{Scheme: "spiffe", Host: "cluster.local", Path: "/ns/default/sa/web"}}},
+		},
+		"uri-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedURIs: []string{`spiffe://cluster\.local/ns/[a-z-]+/sa/[a-z-]+`}},
+			csr:           x509.CertificateRequest{URIs: []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/web"}}},
+			expectFailure: true,
+		},
+		"usages-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth}},
+			spec:   cmapi.CertificateRequestSpec{Usages: []cmapi.KeyUsage{cmapi.UsageServerAuth}},
+		},
+		"default-usages-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment}},
+		},
+		"usage-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageServerAuth}},
+			spec:          cmapi.CertificateRequestSpec{Usages: []cmapi.KeyUsage{cmapi.UsageServerAuth, cmapi.UsageClientAuth}},
+			expectFailure: true,
+		},
+		"signing-allowed-as-digital-signature": {
+			policy: issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageSigning, cmapi.UsageKeyEncipherment}},
+		},
+		"csr-key-usage-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment}},
+			csr:           x509.CertificateRequest{ExtraExtensions: keyUsage(x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign)},
+			expectFailure: true,
+		},
+		"csr-extended-key-usage-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth}},
+			csr:    x509.CertificateRequest{ExtraExtensions: extKeyUsage([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})},
+		},
+		"csr-extended-key-usage-not-allowed": {
+			policy:        issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth}},
+			csr:           x509.CertificateRequest{ExtraExtensions: extKeyUsage([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth})},
+			expectFailure: true,
+		},
+		"csr-unknown-extended-key-usage": {
+			policy:        issuerapi.IssuancePolicy{AllowedUsages: []cmapi.KeyUsage{cmapi.UsageDigitalSignature, cmapi.UsageKeyEncipherment, cmapi.UsageServerAuth}},
+			csr:           x509.CertificateRequest{ExtraExtensions: extKeyUsage(nil, asn1.ObjectIdentifier{1, 2, 3, 4})},
+			expectFailure: true,
+		},
+		"ca-not-allowed": {
+			spec:          cmapi.CertificateRequestSpec{IsCA: true},
+			expectFailure: true,
+		},
+		"ca-allowed": {
+			policy: issuerapi.IssuancePolicy{AllowCA: true},
+			spec:   cmapi.CertificateRequestSpec{IsCA: true},
+		},
+		"duration-within-maximum": {
+			policy: issuerapi.IssuancePolicy{MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			spec:   cmapi.CertificateRequestSpec{Duration: &metav1.Duration{Duration: time.Hour}},
+		},
+		"duration-over-maximum": {
+			policy:        issuerapi.IssuancePolicy{MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			spec:          cmapi.CertificateRequestSpec{Duration: &metav1.Duration{Duration: 48 * time.Hour}},
+			expectFailure: true,
+		},
+		"default-duration-over-maximum": {
+			policy:          issuerapi.IssuancePolicy{MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			defaultDuration: 48 * time.Hour,
+			expectFailure:   true,
+		},
+		"fallback-duration-over-maximum": {
+			policy:        issuerapi.IssuancePolicy{MaxDuration: &metav1.Duration{Duration: 24 * time.Hour}},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			key := tc.key
+			if key == nil {
+				key = rsaKey
+			}
+			cr := &cmapi.CertificateRequest{Spec: tc.spec}
+			cr.Spec.Request = policyTestCSR(t, key, tc.csr)
+
+			csr, err := parseCertificateRequest(cr)
+			require.NoError(t, err)
+
+			policy, err := newIssuancePolicy(&tc.policy)
+			require.NoError(t, err)
+
+			duration, _, err := validityOptions{defaultDuration: tc.defaultDuration}.duration(cr)
+			require.NoError(t, err)
+
+			err = policy.check(cr, csr, duration)
+			if tc.expectFailure {
+				assert.ErrorIs(t, err, ErrIssuancePolicy)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateIssuancePolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy        *issuerapi.IssuancePolicy
+		expectFailure bool
+	}{
+		"unset": {},
+		"valid": {
+			policy: &issuerapi.IssuancePolicy{
+				AllowedKeyAlgorithms:  []issuerapi.KeyAlgorithmPolicy{{Algorithm: "ECDSA", MinSize: 256}},
+				AllowedDNSNames:       []string{`.*\.example\.com`},
+				AllowedDomainSuffixes: []string{"example.org"},
+				AllowedIPRanges:       []string{"10.0.0.0/8", "fd00::/8"},
+				AllowedURIs:           []string{`spiffe://.*`},
+			},
+		},
+		"unknown-key-algorithm": {
+			policy:        &issuerapi.IssuancePolicy{AllowedKeyAlgorithms: []issuerapi.KeyAlgorithmPolicy{{Algorithm: "DSA"}}},
+			expectFailure: true,
+		},
+		"invalid-dns-name-pattern": {
+			policy:        &issuerapi.IssuancePolicy{AllowedDNSNames: []string{`(`}},
+			expectFailure: true,
+		},
+		"empty-domain-suffix": {
+			policy:        &issuerapi.IssuancePolicy{AllowedDomainSuffixes: []string{"."}},
+			expectFailure: true,
+		},
+		"invalid-ip-range": {
+			policy:        &issuerapi.IssuancePolicy{AllowedIPRanges: []string{"10.0.0.1"}},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateIssuancePolicy(tc.policy)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPCASignRejectedRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	valid := policyTestCSR(t, key, x509.CertificateRequest{DNSNames: []string{"www.example.org"}})
+
+	// Flip a bit of the signature, which is at the end of the CSR
+	block, _ := pem.Decode(valid)
+	block.Bytes[len(block.Bytes)-1] ^= 1
+	tampered := pem.EncodeToMemory(block)
+
+	policy, err := newIssuancePolicy(&issuerapi.IssuancePolicy{AllowedDomainSuffixes: []string{"example.com"}})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		request       []byte
+		expectedError error
+	}{
+		"not-pem": {
+			request:       []byte("not a CSR"),
+			expectedError: ErrInvalidRequest,
+		},
+		"bad-signature": {
+			request:       tampered,
+			expectedError: ErrInvalidRequest,
+		},
+		"policy-violation": {
+			request:       valid,
+			expectedError: ErrIssuancePolicy,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &workingACMPCAClient{}
+			provisioner := PCAProvisioner{arn: arn, pcaClient: client, issuancePolicy: policy}
+
+			cr := &cmapi.CertificateRequest{Spec: cmapi.CertificateRequestSpec{Request: tc.request}}
+			err := provisioner.Sign(context.TODO(), cr, logr.Discard())
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.Nil(t, client.issueCertInput, "expected PCA not to be called")
+		})
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Operations of PCA that are rate limited. Their quotas are separate, so each
+// has its own buckets.
+const (
+	operationIssueCertificate  = "IssueCertificate"
+	operationGetCertificate    = "GetCertificate"
+	operationRevokeCertificate = "RevokeCertificate"
+)
+
+// reservationTTL is how long a reservation is kept for a CertificateRequest
+// that does not come back to use it, e.g. because it was deleted
+const reservationTTL = 10 * time.Minute
+
+// RateLimit is a token bucket. A RequestsPerSecond of 0 disables the bucket,
+// and a Burst of 0 defaults to RequestsPerSecond.
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimits are the token buckets limiting the calls made to each operation
+// of PCA
+type RateLimits struct {
+	// Account limits the calls to all the CAs of an account in a region
+	Account RateLimit
+	// CA limits the calls to each CA, unless its issuer overrides the limit
+	CA RateLimit
+}
+
+// DefaultRateLimits stay below the default PCA quotas of an account in a region
+var DefaultRateLimits = RateLimits{
+	Account: RateLimit{RequestsPerSecond: 20, Burst: 40},
+}
+
+// RateLimitedError is returned instead of calling PCA when a rate limit has
+// been reached. The call is reserved for the CertificateRequest, which may
+// make it after RetryAfter.
+type RateLimitedError struct {
+	Operation  string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("rate limit of %s reached, retry after %s", e.Operation, e.RetryAfter)
+}
+
+var rateLimitWait = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "awspca_issuer_rate_limit_wait_seconds",
+	Help:    "How long CertificateRequests wait for the rate limit before calling PCA.",
+	Buckets: []float64{0, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+}, []string{"operation"})
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(rateLimitWait)
+}
+
+// rateLimiter is shared by all provisioners, since CAs of an account share
+// its quotas
+var rateLimiter = NewRateLimiter(DefaultRateLimits)
+
+// SetRateLimits replaces the rate limits of all provisioners created afterwards
+func SetRateLimits(limits RateLimits) {
+	rateLimiter = NewRateLimiter(limits)
+}
+
+// RateLimiter limits the calls made to PCA by CertificateRequests without
+// blocking them. A CertificateRequest that finds a bucket empty reserves the
+// call and is told when to come back, so that requests waiting for the same
+// bucket are spread out instead of retrying together.
+type RateLimiter struct {
+	mu           sync.Mutex
+	limits       RateLimits
+	buckets      map[string]*rate.Limiter
+	reservations map[string]time.Time
+	clock        func() time.Time
+}
+
+func NewRateLimiter(limits RateLimits) *RateLimiter {
+	return &RateLimiter{
+		limits:       limits,
+		buckets:      map[string]*rate.Limiter{},
+		reservations: map[string]time.Time{},
+	}
+}
+
+// reserve takes a token for a call of a CertificateRequest to an operation of
+// a CA, or returns a RateLimitedError once the call has been reserved. A nil
+// limiter allows every call.
+func (l *RateLimiter) reserve(cr *cmapi.CertificateRequest, operation, caArn string, caLimit *api.RateLimit) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	key := operation + "/" + cr.Namespace + "/" + cr.Name
+	if allowedAt, ok := l.reservations[key]; ok {
+		if wait := allowedAt.Sub(now); wait > 0 {
+			return &RateLimitedError{Operation: operation, RetryAfter: wait}
+		}
+		delete(l.reservations, key)
+		return nil
+	}
+
+	var wait time.Duration
+	for _, bucket := range l.bucketsFor(now, operation, caArn, caLimit) {
+		wait = max(wait, bucket.ReserveN(now, 1).DelayFrom(now))
+	}
+	rateLimitWait.WithLabelValues(operation).Observe(wait.Seconds())
+	if wait == 0 {
+		return nil
+	}
+
+	for k, allowedAt := range l.reservations {
+		if now.Sub(allowedAt) > reservationTTL {
+			delete(l.reservations, k)
+		}
+	}
+	l.reservations[key] = now.Add(wait)
+	return &RateLimitedError{Operation: operation, RetryAfter: wait}
+}
+
+// bucketsFor returns the buckets a call to an operation of a CA takes tokens
+// from: the bucket of the CA's account and region, and the bucket of the CA
+func (l *RateLimiter) bucketsFor(now time.Time, operation, caArn string, caLimit *api.RateLimit) []*rate.Limiter {
+	var buckets []*rate.Limiter
+	if ca, err := ParseCertificateAuthorityArn(caArn); err == nil {
+		if bucket := l.bucket(now, operation+"/"+ca.AccountID+"/"+ca.Region, l.limits.Account); bucket != nil {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	limit := l.limits.CA
+	if caLimit != nil {
+		limit = RateLimit{RequestsPerSecond: float64(caLimit.RequestsPerSecond), Burst: int(caLimit.Burst)}
+	}
+	if bucket := l.bucket(now, operation+"/"+caArn, limit); bucket != nil {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// bucket returns the bucket for a key, updated to the given limit
+func (l *RateLimiter) bucket(now time.Time, key string, limit RateLimit) *rate.Limiter {
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = max(1, int(math.Ceil(limit.RequestsPerSecond)))
+	}
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+		l.buckets[key] = bucket
+		return bucket
+	}
+	if bucket.Limit() != rate.Limit(limit.RequestsPerSecond) {
+		bucket.SetLimitAt(now, rate.Limit(limit.RequestsPerSecond))
+	}
+	if bucket.Burst() != burst {
+		bucket.SetBurstAt(now, burst)
+	}
+	return bucket
+}
+
+func (l *RateLimiter) now() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const otherArn = "arn:aws:acm-pca:us-east-1:account:certificate-authority/87654321-4321-4321-4321-210987654321"
+
+func rateLimitedRequest(name string) *cmapi.CertificateRequest {
+	return &cmapi.CertificateRequest{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name}}
+}
+
+// retryAfter returns how long a rate limited call has to wait, or 0 if it
+// was allowed
+func retryAfter(t *testing.T, err error) time.Duration {
+	if err == nil {
+		return 0
+	}
+	var rateLimitedErr *RateLimitedError
+	require.True(t, errors.As(err, &rateLimitedErr), "unexpected error %v", err)
+	return rateLimitedErr.RetryAfter
+}
+
+func TestRateLimiter(t *testing.T) {
+	type call struct {
+		after      time.Duration
+		request    string
+		operation  string
+		arn        string
+		caLimit    *api.RateLimit
+		retryAfter time.Duration
+	}
+
+	type testCase struct {
+		limits RateLimits
+		calls  []call
+	}
+
+	tests := map[string]testCase{
+		"account-limit-spreads-requests": {
+			limits: RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 2}},
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr2"},
+				{request: "cr3", retryAfter: time.Second},
+				{request: "cr4", retryAfter: 2 * time.Second},
+			},
+		},
+		"account-limit-is-shared-by-cas": {
+			limits: RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 1}},
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr2", arn: otherArn, retryAfter: time.Second},
+			},
+		},
+		"operations-have-separate-buckets": {
+			limits: RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 1}},
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr1", operation: operationGetCertificate},
+				{request: "cr2", retryAfter: time.Second},
+			},
+		},
+		"reserved-call-is-made-later": {
+			limits: RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 1}},
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr2", retryAfter: time.Second},
+				{after: 500 * time.Millisecond, request: "cr2", retryAfter: 500 * time.Millisecond},
+				{after: time.Second, request: "cr2"},
+				{after: time.Second, request: "cr3", retryAfter: time.Second},
+			},
+		},
+		"ca-limit": {
+			limits: RateLimits{CA: RateLimit{RequestsPerSecond: 2}},
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr2"},
+				{request: "cr3", retryAfter: 500 * time.Millisecond},
+				{request: "cr4", arn: otherArn},
+			},
+		},
+		"issuer-overrides-ca-limit": {
+			limits: RateLimits{CA: RateLimit{RequestsPerSecond: 100}},
+			calls: []call{
+				{request: "cr1", caLimit: &api.RateLimit{RequestsPerSecond: 1, Burst: 1}},
+				{request: "cr2", caLimit: &api.RateLimit{RequestsPerSecond: 1, Burst: 1}, retryAfter: time.Second},
+			},
+		},
+		"disabled": {
+			calls: []call{
+				{request: "cr1"},
+				{request: "cr2"},
+				{request: "cr3"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			now := start
+			limiter := NewRateLimiter(tc.limits)
+			limiter.clock = func() time.Time { return now }
+
+			for i, c := range tc.calls {
+				now = start.Add(c.after)
+				operation := c.operation
+				if operation == "" {
+					operation = operationIssueCertificate
+				}
+				caArn := c.arn
+				if caArn == "" {
+					caArn = arn
+				}
+
+				err := limiter.reserve(rateLimitedRequest(c.request), operation, caArn, c.caLimit)
+				assert.Equal(t, c.retryAfter, retryAfter(t, err), "call %d", i)
+			}
+		})
+	}
+}
+
+func TestNilRateLimiter(t *testing.T) {
+	var limiter *RateLimiter
+	assert.NoError(t, limiter.reserve(rateLimitedRequest("cr1"), operationIssueCertificate, arn, nil))
+}
+
+func TestPCASignRateLimited(t *testing.T) {
+	client := &workingACMPCAClient{}
+	limiter := NewRateLimiter(RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 1}})
+	provisioner := PCAProvisioner{arn: arn, pcaClient: client, limiter: limiter}
+	ctx := context.TODO()
+
+	first := signingTestRequest(t, nil)
+	first.Name = "cr1"
+	require.NoError(t, provisioner.Sign(ctx, first, logr.Discard()))
+	require.NotNil(t, client.issueCertInput)
+
+	client.issueCertInput = nil
+	second := signingTestRequest(t, nil)
+	second.Name = "cr2"
+	err := provisioner.Sign(ctx, second, logr.Discard())
+	assert.Greater(t, retryAfter(t, err), time.Duration(0))
+	assert.Nil(t, client.issueCertInput, "expected PCA not to be called")
+}
+
+func TestPCARevokeRateLimited(t *testing.T) {
+	type testCase struct {
+		certificate       []byte
+		expectedOperation string
+	}
+
+	tests := map[string]testCase{
+		"revoke": {
+			certificate:       []byte(cert),
+			expectedOperation: operationRevokeCertificate,
+		},
+		"certificate-from-pca": {
+			expectedOperation: operationGetCertificate,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &workingACMPCAClient{}
+			limiter := NewRateLimiter(RateLimits{Account: RateLimit{RequestsPerSecond: 1, Burst: 1}})
+			provisioner := PCAProvisioner{arn: arn, pcaClient: client, limiter: limiter}
+			ctx := context.TODO()
+
+			first := rateLimitedRequest("cr1")
+			first.Status.Certificate = tc.certificate
+			require.NoError(t, provisioner.Revoke(ctx, first, certArn, "", logr.Discard()))
+			require.NotNil(t, client.revokeCertInput)
+
+			client.revokeCertInput = nil
+			second := rateLimitedRequest("cr2")
+			second.Status.Certificate = tc.certificate
+			err := provisioner.Revoke(ctx, second, certArn, "", logr.Discard())
+			var rateLimitedErr *RateLimitedError
+			require.True(t, errors.As(err, &rateLimitedErr), "unexpected error %v", err)
+			assert.Equal(t, tc.expectedOperation, rateLimitedErr.Operation)
+			assert.Nil(t, client.revokeCertInput, "expected PCA not to be called")
+		})
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsarn "github.com/aws/aws-sdk-go-v2/aws/arn"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	rolesAnywhereCredentialsSource = "IAMRolesAnywhere"
+	rolesAnywhereService           = "rolesanywhere"
+	rolesAnywhereDefaultDuration   = time.Hour
+	rolesAnywhereTimeFormat        = "20060102T150405Z"
+	rolesAnywhereDateFormat        = "20060102"
+)
+
+// ValidateRolesAnywhere checks the parts of RolesAnywhere that cannot be
+// expressed in the CRD schema
+func ValidateRolesAnywhere(spec *api.AWSPCAIssuerSpec) error {
+	ra := spec.RolesAnywhere
+	if ra == nil {
+		return nil
+	}
+	if spec.SecretRef.Name != "" {
+		return errors.New("rolesAnywhere and secretRef cannot both be set")
+	}
+	if ra.CertificateSecretRef.Name == "" {
+		return errors.New("rolesAnywhere requires certificateSecretRef")
+	}
+	for field, value := range map[string]string{"trustAnchorArn": ra.TrustAnchorArn, "profileArn": ra.ProfileArn, "roleArn": ra.RoleArn} {
+		if !awsarn.IsARN(value) {
+			return fmt.Errorf("rolesAnywhere %s %q is not an ARN", field, value)
+		}
+	}
+	if ra.Duration != nil && (ra.Duration.Duration < minAssumeRoleDuration || ra.Duration.Duration > maxAssumeRoleDuration) {
+		return fmt.Errorf("rolesAnywhere duration %s is not between %s and %s", ra.Duration.Duration, minAssumeRoleDuration, maxAssumeRoleDuration)
+	}
+	return nil
+}
+
+// rolesAnywhereProvider retrieves credentials from IAM Roles Anywhere by
+// signing CreateSession requests with a certificate held in a Secret. The
+// Secret is read on every call to Retrieve, so it is expected to be wrapped in
+// an aws.CredentialsCache.
+type rolesAnywhereProvider struct {
+	client     client.Client
+	httpClient *http.Client
+	config     api.RolesAnywhere
+	endpoint   string
+	region     string
+	now        func() time.Time
+}
+
+func newRolesAnywhereProvider(client client.Client, spec *api.AWSPCAIssuerSpec) *rolesAnywhereProvider {
+	ra := spec.RolesAnywhere
+
+	// Sessions are created in the region and partition of the trust anchor
+	region := issuerRegion(spec)
+	partition := defaultPartition
+	if parsed, err := awsarn.Parse(ra.TrustAnchorArn); err == nil {
+		if parsed.Region != "" {
+			region = parsed.Region
+		}
+		partition = parsed.Partition
+	}
+
+	endpoint := fmt.Sprintf("https://%s.%s.%s", rolesAnywhereService, region, dnsSuffix(partition))
+	if spec.Endpoints != nil && spec.Endpoints.RolesAnywhere != "" {
+		endpoint = strings.TrimSuffix(spec.Endpoints.RolesAnywhere, "/")
+	}
+
+	return &rolesAnywhereProvider{
+		client:     client,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		config:     *ra,
+		endpoint:   endpoint,
+		region:     region,
+		now:        time.Now,
+	}
+}
+
+type createSessionInput struct {
+	DurationSeconds int32  `json:"durationSeconds"`
+	ProfileArn      string `json:"profileArn"`
+	RoleArn         string `json:"roleArn"`
+	TrustAnchorArn  string `json:"trustAnchorArn"`
+}
+
+type createSessionOutput struct {
+	CredentialSet []struct {
+		Credentials struct {
+			AccessKeyID     string `json:"accessKeyId"`
+			SecretAccessKey string `json:"secretAccessKey"`
+			SessionToken    string `json:"sessionToken"`
+			Expiration      string `json:"expiration"`
+		} `json:"credentials"`
+	} `json:"credentialSet"`
+}
+
+// Retrieve creates a Roles Anywhere session with the certificate from the Secret
+func (p *rolesAnywhereProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	cert, err := p.loadCertificate(ctx)
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	duration := rolesAnywhereDefaultDuration
+	if p.config.Duration != nil {
+		duration = p.config.Duration.Duration
+	}
+	body, err := json.Marshal(createSessionInput{
+		DurationSeconds: int32(duration.Seconds()),
+		ProfileArn:      p.config.ProfileArn,
+		RoleArn:         p.config.RoleArn,
+		TrustAnchorArn:  p.config.TrustAnchorArn,
+	})
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/sessions", bytes.NewReader(body))
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := signRolesAnywhereRequest(req, body, cert, p.region, p.now()); err != nil {
+		return aws.Credentials{}, err
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to create Roles Anywhere session: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to read Roles Anywhere session: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return aws.Credentials{}, fmt.Errorf("failed to create Roles Anywhere session: %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var out createSessionOutput
+	if err := json.Unmarshal(respBody, &out); err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to parse Roles Anywhere session: %v", err)
+	}
+	if len(out.CredentialSet) == 0 {
+		return aws.Credentials{}, errors.New("no credentials in Roles Anywhere session")
+	}
+
+	creds := out.CredentialSet[0].Credentials
+	expires, err := time.Parse(time.RFC3339, creds.Expiration)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to parse Roles Anywhere credentials expiration: %v", err)
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Source:          rolesAnywhereCredentialsSource,
+		CanExpire:       true,
+		Expires:         expires,
+	}, nil
+}
+
+// loadCertificate reads the certificate, its chain and private key from the Secret
+func (p *rolesAnywhereProvider) loadCertificate(ctx context.Context) (*tls.Certificate, error) {
+	secretNamespaceName := types.NamespacedName{
+		Namespace: p.config.CertificateSecretRef.Namespace,
+		Name:      p.config.CertificateSecretRef.Name,
+	}
+
+	secret := new(core.Secret)
+	if err := p.client.Get(ctx, secretNamespaceName, secret); err != nil {
+		return nil, fmt.Errorf("failed to retrieve secret: %v", err)
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[core.TLSCertKey], secret.Data[core.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Roles Anywhere certificate from secret %s: %v", secretNamespaceName, err)
+	}
+	return &cert, nil
+}
+
+// signRolesAnywhereRequest signs req with the private key of cert, following
+// the SigV4 X.509 scheme used by IAM Roles Anywhere
+func signRolesAnywhereRequest(req *http.Request, body []byte, cert *tls.Certificate, region string, now time.Time) error {
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse Roles Anywhere certificate: %v", err)
+	}
+
+	var algorithm string
+	switch cert.PrivateKey.(type) {
+	case *rsa.PrivateKey:
+		algorithm = "AWS4-X509-RSA-SHA256"
+	case *ecdsa.PrivateKey:
+		algorithm = "AWS4-X509-ECDSA-SHA256"
+	default:
+		return fmt.Errorf("unsupported Roles Anywhere private key type %T", cert.PrivateKey)
+	}
+
+	now = now.UTC()
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", now.Format(rolesAnywhereTimeFormat))
+	req.Header.Set("X-Amz-X509", base64.StdEncoding.EncodeToString(leaf.Raw))
+	if len(cert.Certificate) > 1 {
+		chain := make([]string, 0, len(cert.Certificate)-1)
+		for _, der := range cert.Certificate[1:] {
+			chain = append(chain, base64.StdEncoding.EncodeToString(der))
+		}
+		req.Header.Set("X-Amz-X509-Chain", strings.Join(chain, ","))
+	}
+
+	canonicalRequest, signedHeaders := rolesAnywhereCanonicalRequest(req, body)
+	scope := strings.Join([]string{now.Format(rolesAnywhereDateFormat), region, rolesAnywhereService, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{algorithm, now.Format(rolesAnywhereTimeFormat), scope, hexSHA256([]byte(canonicalRequest))}, "\n")
+
+	digest := sha256.Sum256([]byte(stringToSign))
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("unsupported Roles Anywhere private key type %T", cert.PrivateKey)
+	}
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return fmt.Errorf("failed to sign Roles Anywhere request: %v", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, leaf.SerialNumber.String(), scope, signedHeaders, hex.EncodeToString(signature)))
+	return nil
+}
+
+// rolesAnywhereCanonicalRequest returns the SigV4 canonical request and the
+// list of signed headers
+func rolesAnywhereCanonicalRequest(req *http.Request, body []byte) (string, string) {
+	headers := []string{"content-type", "host", "x-amz-date", "x-amz-x509"}
+	if req.Header.Get("X-Amz-X509-Chain") != "" {
+		headers = append(headers, "x-amz-x509-chain")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, header := range headers {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", header, strings.TrimSpace(req.Header.Get(header)))
+	}
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	signedHeaders := strings.Join(headers, ";")
+	return strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n"), signedHeaders
+}
+
+func canonicalQuery(query url.Values) string {
+	return strings.ReplaceAll(query.Encode(), "+", "%20")
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
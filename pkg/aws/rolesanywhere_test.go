@@ -0,0 +1,339 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	trustAnchorArn       = "arn:aws:rolesanywhere:eu-west-1:123456789012:trust-anchor/0123"
+	profileArn           = "arn:aws:rolesanywhere:eu-west-1:123456789012:profile/4567"
+	rolesAnywhereRoleArn = "arn:aws:iam::123456789012:role/pca"
+)
+
+// rolesAnywhereSecret returns a TLS Secret holding a certificate issued by an
+// intermediate, and the intermediate itself
+func rolesAnywhereSecret(t *testing.T, key crypto.Signer) *v1.Secret {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "intermediate"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(4660),
+		Subject:      pkix.Name{CommonName: "aws-privateca-issuer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, caTemplate, key.Public(), caKey)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...,
+	)
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "rolesanywhere", Namespace: "ns1"},
+		Type:       v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       certPEM,
+			v1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+}
+
+// verifyRolesAnywhereRequest checks the signature of a CreateSession request
+// against the certificate it carries
+func verifyRolesAnywhereRequest(t *testing.T, r *http.Request, body []byte) {
+	leafDER, err := base64.StdEncoding.DecodeString(r.Header.Get("X-Amz-X509"))
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+	assert.NotEmpty(t, r.Header.Get("X-Amz-X509-Chain"))
+
+	authorization := r.Header.Get("Authorization")
+	algorithm, rest, _ := strings.Cut(authorization, " ")
+	fields := map[string]string{}
+	for _, field := range strings.Split(rest, ", ") {
+		k, v, _ := strings.Cut(field, "=")
+		fields[k] = v
+	}
+
+	date := r.Header.Get("X-Amz-Date")
+	scope := date[:8] + "/eu-west-1/rolesanywhere/aws4_request"
+	assert.Equal(t, "4660/"+scope, fields["Credential"])
+	assert.Equal(t, "content-type;host;x-amz-date;x-amz-x509;x-amz-x509-chain", fields["SignedHeaders"])
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := "POST\n/sessions\n\n" +
+		"content-type:" + r.Header.Get("Content-Type") + "\n" +
+		"host:" + r.Host + "\n" +
+		"x-amz-date:" + date + "\n" +
+		"x-amz-x509:" + r.Header.Get("X-Amz-X509") + "\n" +
+		"x-amz-x509-chain:" + r.Header.Get("X-Amz-X509-Chain") + "\n\n" +
+		fields["SignedHeaders"] + "\n" +
+		hex.EncodeToString(bodyHash[:])
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	digest := sha256.Sum256([]byte(algorithm + "\n" + date + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])))
+
+	signature, err := hex.DecodeString(fields["Signature"])
+	require.NoError(t, err)
+	switch pub := leaf.PublicKey.(type) {
+	case *rsa.PublicKey:
+		assert.Equal(t, "AWS4-X509-RSA-SHA256", algorithm)
+		assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature))
+	case *ecdsa.PublicKey:
+		assert.Equal(t, "AWS4-X509-ECDSA-SHA256", algorithm)
+		assert.True(t, ecdsa.VerifyASN1(pub, digest[:], signature), "invalid ECDSA signature")
+	}
+}
+
+func TestGetConfigRolesAnywhere(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]crypto.Signer{
+		"rsa":   rsaKey,
+		"ecdsa": ecKey,
+	}
+
+	for name, key := range tests {
+		t.Run(name, func(t *testing.T) {
+			sessions := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sessions++
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/sessions", r.URL.Path)
+
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				verifyRolesAnywhereRequest(t, r, body)
+
+				var input map[string]interface{}
+				require.NoError(t, json.Unmarshal(body, &input))
+				assert.Equal(t, map[string]interface{}{
+					"durationSeconds": float64(1800),
+					"profileArn":      profileArn,
+					"roleArn":         rolesAnywhereRoleArn,
+					"trustAnchorArn":  trustAnchorArn,
+				}, input)
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprintf(w, `{"credentialSet":[{"credentials":{"accessKeyId":"ra-access","secretAccessKey":"ra-secret","sessionToken":"ra-session","expiration":%q}}]}`,
+					time.Now().Add(30*time.Minute).UTC().Format(time.RFC3339))
+			}))
+			defer server.Close()
+
+			fakeClient := fake.NewClientBuilder().WithObjects(rolesAnywhereSecret(t, key)).Build()
+
+			ctx := context.TODO()
+			cfg, err := GetConfig(ctx, fakeClient, types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &issuerapi.AWSPCAIssuerSpec{
+				Region: "us-east-1",
+				RolesAnywhere: &issuerapi.RolesAnywhere{
+					CertificateSecretRef: v1.SecretReference{Name: "rolesanywhere", Namespace: "ns1"},
+					TrustAnchorArn:       trustAnchorArn,
+					ProfileArn:           profileArn,
+					RoleArn:              rolesAnywhereRoleArn,
+					Duration:             &metav1.Duration{Duration: 30 * time.Minute},
+				},
+				Endpoints: &issuerapi.AWSEndpoints{RolesAnywhere: server.URL},
+			})
+			require.NoError(t, err)
+
+			creds, err := cfg.Credentials.Retrieve(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, "ra-access", creds.AccessKeyID)
+			assert.Equal(t, "ra-secret", creds.SecretAccessKey)
+			assert.Equal(t, "ra-session", creds.SessionToken)
+			assert.True(t, creds.CanExpire)
+
+			// The session created while loading the config is reused
+			assert.Equal(t, 1, sessions)
+		})
+	}
+}
+
+func TestGetConfigRolesAnywhereRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Untrusted certificate. Insufficient certificate"}`))
+	}))
+	defer server.Close()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	fakeClient := fake.NewClientBuilder().WithObjects(rolesAnywhereSecret(t, key)).Build()
+
+	_, err = GetConfig(context.TODO(), fakeClient, types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &issuerapi.AWSPCAIssuerSpec{
+		Region: "us-east-1",
+		RolesAnywhere: &issuerapi.RolesAnywhere{
+			CertificateSecretRef: v1.SecretReference{Name: "rolesanywhere", Namespace: "ns1"},
+			TrustAnchorArn:       trustAnchorArn,
+			ProfileArn:           profileArn,
+			RoleArn:              rolesAnywhereRoleArn,
+		},
+		Endpoints: &issuerapi.AWSEndpoints{RolesAnywhere: server.URL},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Untrusted certificate")
+}
+
+func TestValidateRolesAnywhere(t *testing.T) {
+	valid := func() *issuerapi.AWSPCAIssuerSpec {
+		return &issuerapi.AWSPCAIssuerSpec{
+			RolesAnywhere: &issuerapi.RolesAnywhere{
+				CertificateSecretRef: v1.SecretReference{Name: "rolesanywhere", Namespace: "ns1"},
+				TrustAnchorArn:       trustAnchorArn,
+				ProfileArn:           profileArn,
+				RoleArn:              rolesAnywhereRoleArn,
+			},
+		}
+	}
+
+	type testCase struct {
+		spec          func() *issuerapi.AWSPCAIssuerSpec
+		expectFailure bool
+	}
+
+	tests := map[string]testCase{
+		"not-set": {
+			spec: func() *issuerapi.AWSPCAIssuerSpec { return &issuerapi.AWSPCAIssuerSpec{} },
+		},
+		"valid": {
+			spec: valid,
+		},
+		"with-secret-ref": {
+			spec: func() *issuerapi.AWSPCAIssuerSpec {
+				spec := valid()
+				spec.SecretRef.Name = "credentials"
+				return spec
+			},
+			expectFailure: true,
+		},
+		"invalid-profile-arn": {
+			spec: func() *issuerapi.AWSPCAIssuerSpec {
+				spec := valid()
+				spec.RolesAnywhere.ProfileArn = "profile/4567"
+				return spec
+			},
+			expectFailure: true,
+		},
+		"duration-too-long": {
+			spec: func() *issuerapi.AWSPCAIssuerSpec {
+				spec := valid()
+				spec.RolesAnywhere.Duration = &metav1.Duration{Duration: 13 * time.Hour}
+				return spec
+			},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateRolesAnywhere(tc.spec())
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRolesAnywhereDefaultEndpoint(t *testing.T) {
+	type testCase struct {
+		trustAnchorArn   string
+		expectedEndpoint string
+		expectedRegion   string
+	}
+
+	tests := map[string]testCase{
+		"aws": {
+			trustAnchorArn:   trustAnchorArn,
+			expectedEndpoint: "https://rolesanywhere.eu-west-1.amazonaws.com",
+			expectedRegion:   "eu-west-1",
+		},
+		"aws-cn": {
+			trustAnchorArn:   "arn:aws-cn:rolesanywhere:cn-north-1:123456789012:trust-anchor/0123",
+			expectedEndpoint: "https://rolesanywhere.cn-north-1.amazonaws.com.cn",
+			expectedRegion:   "cn-north-1",
+		},
+		"aws-us-gov": {
+			trustAnchorArn:   "arn:aws-us-gov:rolesanywhere:us-gov-west-1:123456789012:trust-anchor/0123",
+			expectedEndpoint: "https://rolesanywhere.us-gov-west-1.amazonaws.com",
+			expectedRegion:   "us-gov-west-1",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			provider := newRolesAnywhereProvider(fake.NewClientBuilder().Build(), &issuerapi.AWSPCAIssuerSpec{
+				Region: "us-east-1",
+				RolesAnywhere: &issuerapi.RolesAnywhere{
+					TrustAnchorArn: tc.trustAnchorArn,
+					ProfileArn:     profileArn,
+					RoleArn:        rolesAnywhereRoleArn,
+				},
+			})
+			assert.Equal(t, tc.expectedEndpoint, provider.endpoint)
+			assert.Equal(t, tc.expectedRegion, provider.region)
+		})
+	}
+}
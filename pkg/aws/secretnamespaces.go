@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterResourceNamespace is the only namespace cluster issuers and cluster
+// credentials may read Secrets from. Empty allows any namespace.
+var clusterResourceNamespace string
+
+// SetClusterResourceNamespace restricts the Secrets read by cluster issuers and
+// cluster credentials to a namespace. Empty allows any namespace.
+func SetClusterResourceNamespace(namespace string) {
+	clusterResourceNamespace = namespace
+}
+
+// IssuerSecrets returns the Secrets referenced by spec.secretRef and spec.rolesAnywhere
+func IssuerSecrets(spec *api.AWSPCAIssuerSpec) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	if ref := spec.SecretRef; ref.Name != "" {
+		secrets = append(secrets, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+	}
+	if spec.RolesAnywhere != nil {
+		ref := spec.RolesAnywhere.CertificateSecretRef
+		secrets = append(secrets, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+	}
+	return secrets
+}
+
+// ValidateSecretNamespaces checks the namespaces of the Secrets read by an
+// issuer, or by the shared credentials it refers to. Loading the config of an
+// issuer runs the same checks, so that they hold before the issuer is verified.
+func ValidateSecretNamespaces(ctx context.Context, c client.Client, name types.NamespacedName, spec *api.AWSPCAIssuerSpec) error {
+	if spec.CredentialsRef == nil {
+		return validateSecretNamespaces(issuerSessionNameData(name), IssuerSecrets(spec))
+	}
+
+	kind, credentials, credentialsSpec, err := GetCredentials(ctx, c, name, spec.CredentialsRef)
+	if err != nil {
+		return err
+	}
+	owner := sessionNameData{Kind: kind, Name: credentials.GetName(), Namespace: credentials.GetNamespace()}
+	return validateSecretNamespaces(owner, CredentialsSecrets(credentialsSpec))
+}
+
+// validateSecretNamespaces checks that namespaced issuers and credentials only
+// read Secrets from their own namespace, and that cluster issuers and
+// credentials only read Secrets from the cluster resource namespace
+func validateSecretNamespaces(owner sessionNameData, secrets []types.NamespacedName) error {
+	if owner.Namespace == "" {
+		if clusterResourceNamespace == "" {
+			return nil
+		}
+		for _, secret := range secrets {
+			if secret.Namespace != clusterResourceNamespace {
+				return fmt.Errorf("%s %s cannot read secret %s, cluster resources can only read secrets in namespace %s", owner.Kind, owner.Name, secret, clusterResourceNamespace)
+			}
+		}
+		return nil
+	}
+
+	for _, secret := range secrets {
+		if secret.Namespace != owner.Namespace {
+			return fmt.Errorf("%s %s cannot read secret %s, namespaced resources can only read secrets in their own namespace %s", owner.Kind, owner.Name, secret, owner.Namespace)
+		}
+	}
+	return nil
+}
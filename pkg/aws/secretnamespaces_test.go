@@ -0,0 +1,110 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"testing"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// TestGetConfigSecretNamespaces checks that loading the config enforces the
+// Secret namespaces itself, since CertificateRequests load it without the
+// issuer being verified first
+func TestGetConfigSecretNamespaces(t *testing.T) {
+	type testCase struct {
+		name                     types.NamespacedName
+		spec                     issuerapi.AWSPCAIssuerSpec
+		clusterResourceNamespace string
+		expectFailure            bool
+	}
+
+	secretRef := func(namespace string) issuerapi.AWSCredentialsSecretReference {
+		return issuerapi.AWSCredentialsSecretReference{
+			SecretReference: v1.SecretReference{Namespace: namespace, Name: "credentials"},
+		}
+	}
+	issuer := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+	clusterIssuer := types.NamespacedName{Name: "clusterissuer1"}
+
+	tests := map[string]testCase{
+		"issuer-own-namespace": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{Region: "us-east-1", SecretRef: secretRef("ns1")},
+		},
+		"issuer-other-namespace": {
+			name:          issuer,
+			spec:          issuerapi.AWSPCAIssuerSpec{Region: "us-east-1", SecretRef: secretRef("ns2")},
+			expectFailure: true,
+		},
+		"cluster-issuer-cluster-resource-namespace": {
+			name:                     clusterIssuer,
+			spec:                     issuerapi.AWSPCAIssuerSpec{Region: "us-east-1", SecretRef: secretRef("ns1")},
+			clusterResourceNamespace: "ns1",
+		},
+		"cluster-issuer-outside-cluster-resource-namespace": {
+			name:                     clusterIssuer,
+			spec:                     issuerapi.AWSPCAIssuerSpec{Region: "us-east-1", SecretRef: secretRef("ns2")},
+			clusterResourceNamespace: "ns1",
+			expectFailure:            true,
+		},
+		"credentials-other-namespace": {
+			name:          issuer,
+			spec:          issuerapi.AWSPCAIssuerSpec{CredentialsRef: &issuerapi.CredentialsReference{Name: "other-namespace"}},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resetSharedCredentials(t)
+			SetClusterResourceNamespace(tc.clusterResourceNamespace)
+			t.Cleanup(func() { SetClusterResourceNamespace("") })
+
+			fakeClient := sharedCredentialsClient(t,
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "credentials"},
+					Data: map[string][]byte{
+						"AWS_ACCESS_KEY_ID":     []byte("access"),
+						"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+					},
+				},
+				&v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "credentials"},
+					Data: map[string][]byte{
+						"AWS_ACCESS_KEY_ID":     []byte("access"),
+						"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+					},
+				},
+				&issuerapi.AWSPCACredentials{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "other-namespace"},
+					Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1", SecretRef: secretRef("ns2")},
+				},
+			)
+
+			_, err := GetConfig(context.TODO(), fakeClient, tc.name, &tc.spec)
+			if tc.expectFailure {
+				assert.ErrorContains(t, err, "cannot read secret")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
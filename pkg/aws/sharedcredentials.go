@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// sharedCredentials holds one credentials provider per AWSPCACredentials and
+// AWSPCAClusterCredentials, so that every issuer referring to the same
+// credentials shares their cache
+var sharedCredentials = new(sync.Map)
+
+type sharedCredentialsEntry struct {
+	// fingerprint identifies the version of the credentials object and of the
+	// Secrets it refers to that the provider was created from
+	fingerprint string
+	provider    aws.CredentialsProvider
+}
+
+// ValidateCredentialsRef checks that an issuer referring to shared credentials
+// refers to credentials of its own scope, and does not configure credentials
+// itself
+func ValidateCredentialsRef(issuer types.NamespacedName, spec *api.AWSPCAIssuerSpec) error {
+	if spec.CredentialsRef == nil {
+		return nil
+	}
+	if err := validateCredentialsKind(issuer, credentialsKind(issuer, spec.CredentialsRef)); err != nil {
+		return err
+	}
+	if spec.SecretRef.Name != "" || spec.RolesAnywhere != nil || spec.Role != "" || spec.AssumeRoleOptions != nil || len(spec.RoleChain) > 0 {
+		return errors.New("credentialsRef cannot be combined with secretRef, rolesAnywhere, role, assumeRoleOptions or roleChain")
+	}
+	return nil
+}
+
+// validateCredentialsKind checks that namespaced issuers only refer to
+// AWSPCACredentials in their namespace, and cluster issuers only to
+// AWSPCAClusterCredentials, so that creating an issuer in a namespace does not
+// grant the AWS identity of the cluster
+func validateCredentialsKind(issuer types.NamespacedName, kind string) error {
+	switch {
+	case issuer.Namespace != "" && kind == api.ClusterCredentialsKind:
+		return fmt.Errorf("namespaced issuers cannot refer to %s", kind)
+	case issuer.Namespace == "" && kind == api.CredentialsKind:
+		return fmt.Errorf("cluster issuers cannot refer to %s", kind)
+	}
+	return nil
+}
+
+// CredentialsRefKey identifies the credentials an issuer refers to as
+// Kind/Namespace/Name, or returns "" if the issuer does not refer to any
+func CredentialsRefKey(issuer types.NamespacedName, ref *api.CredentialsReference) string {
+	if ref == nil {
+		return ""
+	}
+	kind := credentialsKind(issuer, ref)
+	namespace := ""
+	if kind == api.CredentialsKind {
+		namespace = issuer.Namespace
+	}
+	return strings.Join([]string{kind, namespace, ref.Name}, "/")
+}
+
+// credentialsKind returns the kind of credentials an issuer refers to
+func credentialsKind(issuer types.NamespacedName, ref *api.CredentialsReference) string {
+	if ref.Kind != "" {
+		return ref.Kind
+	}
+	if issuer.Namespace == "" {
+		return api.ClusterCredentialsKind
+	}
+	return api.CredentialsKind
+}
+
+// GetCredentials retrieves the credentials an issuer refers to, and returns
+// their kind
+func GetCredentials(ctx context.Context, c client.Client, issuer types.NamespacedName, ref *api.CredentialsReference) (string, metav1.Object, *api.AWSPCACredentialsSpec, error) {
+	kind := credentialsKind(issuer, ref)
+	if err := validateCredentialsKind(issuer, kind); err != nil {
+		return "", nil, nil, err
+	}
+
+	switch kind {
+	case api.ClusterCredentialsKind:
+		creds := new(api.AWSPCAClusterCredentials)
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, creds); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to retrieve %s %s: %v", kind, ref.Name, err)
+		}
+		return kind, creds, &creds.Spec, nil
+	case api.CredentialsKind:
+		creds := new(api.AWSPCACredentials)
+		if err := c.Get(ctx, types.NamespacedName{Namespace: issuer.Namespace, Name: ref.Name}, creds); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to retrieve %s %s: %v", kind, ref.Name, err)
+		}
+		return kind, creds, &creds.Spec, nil
+	default:
+		return "", nil, nil, fmt.Errorf("unknown credentials kind %s", kind)
+	}
+}
+
+// credentialsIssuerSpec returns an issuer spec carrying only the credentials configuration
+func credentialsIssuerSpec(spec *api.AWSPCACredentialsSpec) *api.AWSPCAIssuerSpec {
+	return &api.AWSPCAIssuerSpec{
+		Region:            spec.Region,
+		SecretRef:         spec.SecretRef,
+		RolesAnywhere:     spec.RolesAnywhere,
+		Role:              spec.Role,
+		AssumeRoleOptions: spec.AssumeRoleOptions,
+		RoleChain:         spec.RoleChain,
+		Endpoints:         spec.Endpoints,
+	}
+}
+
+// CredentialsSecrets returns the Secrets referred to by credentials
+func CredentialsSecrets(spec *api.AWSPCACredentialsSpec) []types.NamespacedName {
+	var secrets []types.NamespacedName
+	if spec.SecretRef.Name != "" {
+		secrets = append(secrets, types.NamespacedName{Namespace: spec.SecretRef.Namespace, Name: spec.SecretRef.Name})
+	}
+	if spec.RolesAnywhere != nil {
+		ref := spec.RolesAnywhere.CertificateSecretRef
+		secrets = append(secrets, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name})
+	}
+	return secrets
+}
+
+// credentialsFingerprint identifies the version of credentials and the Secrets they refer to
+func credentialsFingerprint(ctx context.Context, c client.Client, obj metav1.Object, spec *api.AWSPCACredentialsSpec) (string, error) {
+	parts := []string{string(obj.GetUID()), fmt.Sprint(obj.GetGeneration())}
+	for _, name := range CredentialsSecrets(spec) {
+		secret := new(core.Secret)
+		if err := c.Get(ctx, name, secret); err != nil {
+			return "", fmt.Errorf("failed to retrieve secret: %v", err)
+		}
+		parts = append(parts, secret.ResourceVersion)
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// sharedCredentialsProvider returns the provider shared by all issuers
+// referring to the given credentials, creating it if the credentials or their
+// Secrets changed since it was created
+func sharedCredentialsProvider(ctx context.Context, c client.Client, kind string, obj metav1.Object, spec *api.AWSPCACredentialsSpec) (aws.CredentialsProvider, error) {
+	key := strings.Join([]string{kind, obj.GetNamespace(), obj.GetName()}, "/")
+	fingerprint, err := credentialsFingerprint(ctx, c, obj, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if value, ok := sharedCredentials.Load(key); ok {
+		if entry := value.(*sharedCredentialsEntry); entry.fingerprint == fingerprint {
+			return entry.provider, nil
+		}
+	}
+
+	credsSpec := credentialsIssuerSpec(spec)
+	if err := validateCredentialsSpec(credsSpec); err != nil {
+		return nil, fmt.Errorf("invalid %s %s: %v", kind, obj.GetName(), err)
+	}
+
+	session := sessionNameData{Kind: kind, Name: obj.GetName(), Namespace: obj.GetNamespace()}
+	cfg, err := loadConfig(ctx, c, session, credsSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedCredentials.Store(key, &sharedCredentialsEntry{fingerprint: fingerprint, provider: cfg.Credentials})
+	return cfg.Credentials, nil
+}
+
+func validateCredentialsSpec(spec *api.AWSPCAIssuerSpec) error {
+	if err := ValidateAssumeRoleOptions(spec.Role, spec.AssumeRoleOptions); err != nil {
+		return err
+	}
+	if err := ValidateRoleChain(spec.Role, spec.RoleChain); err != nil {
+		return err
+	}
+	if err := ValidateEndpoints(spec.Endpoints); err != nil {
+		return err
+	}
+	return ValidateRolesAnywhere(spec)
+}
+
+// loadSharedCredentialsConfig loads the config of an issuer referring to
+// shared credentials
+func loadSharedCredentialsConfig(ctx context.Context, c client.Client, name types.NamespacedName, spec *api.AWSPCAIssuerSpec) (aws.Config, error) {
+	kind, obj, credsSpec, err := GetCredentials(ctx, c, name, spec.CredentialsRef)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	provider, err := sharedCredentialsProvider(ctx, c, kind, obj, credsSpec)
+	if err != nil {
+		return aws.Config{}, err
+	}
+
+	region := issuerRegion(spec)
+	if region == "" {
+		region = credsSpec.Region
+	}
+
+	configOptions := []func(*config.LoadOptions) error{config.WithCredentialsProvider(provider)}
+	if region != "" {
+		configOptions = append(configOptions, config.WithRegion(region))
+	}
+	configOptions = append(configOptions, endpointConfigOptions(spec)...)
+
+	cfg, err := config.LoadDefaultConfig(ctx, configOptions...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if cfg.Region == "" {
+		return aws.Config{}, fmt.Errorf("no Region found in Issuer Spec or %s %s", kind, obj.GetName())
+	}
+	return cfg, nil
+}
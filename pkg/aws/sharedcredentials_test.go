@@ -0,0 +1,246 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func resetSharedCredentials(t *testing.T) {
+	original := sharedCredentials
+	sharedCredentials = new(sync.Map)
+	t.Cleanup(func() { sharedCredentials = original })
+}
+
+func sharedCredentialsClient(t *testing.T, objects ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1.AddToScheme(scheme))
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+func TestGetConfigSharedCredentials(t *testing.T) {
+	const role = "arn:aws:iam::123456789012:role/pca"
+
+	resetSharedCredentials(t)
+	stub := stubSTS(t)
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"},
+		Data: map[string][]byte{
+			"AWS_ACCESS_KEY_ID":     []byte("access"),
+			"AWS_SECRET_ACCESS_KEY": []byte("secret"),
+		},
+	}
+	credentials := &issuerapi.AWSPCACredentials{
+		ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns1"},
+		Spec: issuerapi.AWSPCACredentialsSpec{
+			Region: "us-east-1",
+			SecretRef: issuerapi.AWSCredentialsSecretReference{
+				SecretReference: v1.SecretReference{Name: "credentials", Namespace: "ns1"},
+			},
+			Role:              role,
+			AssumeRoleOptions: &issuerapi.AssumeRoleOptions{SessionName: "{{ .Kind }}-{{ .Namespace }}-{{ .Name }}"},
+		},
+	}
+	fakeClient := sharedCredentialsClient(t, secret, credentials)
+
+	ctx := context.TODO()
+	spec := &issuerapi.AWSPCAIssuerSpec{CredentialsRef: &issuerapi.CredentialsReference{Name: "shared"}}
+	getCredentials := func(issuer string) aws.Config {
+		cfg, err := GetConfig(ctx, fakeClient, types.NamespacedName{Namespace: "ns1", Name: issuer}, spec)
+		require.NoError(t, err)
+		_, err = cfg.Credentials.Retrieve(ctx)
+		require.NoError(t, err)
+		return cfg
+	}
+
+	cfg := getCredentials("issuer1")
+	assert.Equal(t, "us-east-1", cfg.Region)
+	getCredentials("issuer2")
+
+	// Both issuers share the credentials, so the role is only assumed once,
+	// with a session named after the credentials rather than the issuers
+	require.Len(t, stub.inputs, 1)
+	assert.Equal(t, "AWSPCACredentials-ns1-shared", *stub.inputs[0].RoleSessionName)
+
+	// Rotating the secret replaces the shared credentials
+	secret.Data["AWS_SECRET_ACCESS_KEY"] = []byte("rotated")
+	require.NoError(t, fakeClient.Update(ctx, secret))
+	getCredentials("issuer1")
+	getCredentials("issuer2")
+	assert.Len(t, stub.inputs, 2)
+}
+
+func TestGetConfigSharedCredentialsRegion(t *testing.T) {
+	resetSharedCredentials(t)
+
+	fakeClient := sharedCredentialsClient(t,
+		&issuerapi.AWSPCAClusterCredentials{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+			Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1"},
+		},
+	)
+
+	cfg, err := GetConfig(context.TODO(), fakeClient, types.NamespacedName{Name: "clusterissuer1"}, &issuerapi.AWSPCAIssuerSpec{
+		Region:         "eu-west-1",
+		CredentialsRef: &issuerapi.CredentialsReference{Name: "shared"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-1", cfg.Region)
+}
+
+func TestGetConfigSharedCredentialsRejected(t *testing.T) {
+	type testCase struct {
+		name types.NamespacedName
+		ref  issuerapi.CredentialsReference
+	}
+
+	tests := map[string]testCase{
+		"cluster-issuer-namespaced-credentials": {
+			name: types.NamespacedName{Name: "clusterissuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.CredentialsKind},
+		},
+		"missing-credentials": {
+			name: types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "missing"},
+		},
+		"issuer-cluster-credentials": {
+			name: types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.ClusterCredentialsKind},
+		},
+		"credentials-in-other-namespace": {
+			name: types.NamespacedName{Namespace: "ns2", Name: "issuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "shared"},
+		},
+		"no-region": {
+			name: types.NamespacedName{Name: "clusterissuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "no-region"},
+		},
+		"invalid-credentials": {
+			name: types.NamespacedName{Name: "clusterissuer1"},
+			ref:  issuerapi.CredentialsReference{Name: "invalid"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			resetSharedCredentials(t)
+			t.Setenv("AWS_REGION", "")
+
+			fakeClient := sharedCredentialsClient(t,
+				&issuerapi.AWSPCACredentials{
+					ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns1"},
+					Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1"},
+				},
+				&issuerapi.AWSPCAClusterCredentials{
+					ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+					Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1"},
+				},
+				&issuerapi.AWSPCAClusterCredentials{
+					ObjectMeta: metav1.ObjectMeta{Name: "no-region"},
+				},
+				&issuerapi.AWSPCAClusterCredentials{
+					ObjectMeta: metav1.ObjectMeta{Name: "invalid"},
+					Spec: issuerapi.AWSPCACredentialsSpec{
+						Region:            "us-east-1",
+						AssumeRoleOptions: &issuerapi.AssumeRoleOptions{ExternalID: "external"},
+					},
+				},
+			)
+
+			_, err := GetConfig(context.TODO(), fakeClient, tc.name, &issuerapi.AWSPCAIssuerSpec{CredentialsRef: &tc.ref})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateCredentialsRef(t *testing.T) {
+	ref := &issuerapi.CredentialsReference{Name: "shared"}
+	issuer := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+	clusterIssuer := types.NamespacedName{Name: "clusterissuer1"}
+
+	tests := map[string]struct {
+		name          types.NamespacedName
+		spec          issuerapi.AWSPCAIssuerSpec
+		expectFailure bool
+	}{
+		"no-credentials-ref": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{Role: "arn:aws:iam::123456789012:role/pca"},
+		},
+		"credentials-ref": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{CredentialsRef: ref},
+		},
+		"cluster-issuer-credentials-ref": {
+			name: clusterIssuer,
+			spec: issuerapi.AWSPCAIssuerSpec{CredentialsRef: ref},
+		},
+		"issuer-cluster-credentials": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.ClusterCredentialsKind},
+			},
+			expectFailure: true,
+		},
+		"cluster-issuer-namespaced-credentials": {
+			name: clusterIssuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.CredentialsKind},
+			},
+			expectFailure: true,
+		},
+		"credentials-ref-with-secret-ref": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				CredentialsRef: ref,
+				SecretRef: issuerapi.AWSCredentialsSecretReference{
+					SecretReference: v1.SecretReference{Name: "credentials"},
+				},
+			},
+			expectFailure: true,
+		},
+		"credentials-ref-with-role": {
+			name:          issuer,
+			spec:          issuerapi.AWSPCAIssuerSpec{CredentialsRef: ref, Role: "arn:aws:iam::123456789012:role/pca"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCredentialsRef(tc.name, &tc.spec)
+			if tc.expectFailure {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// SigningAlgorithmAnnotation lets a CertificateRequest select the algorithm its
+// certificate is signed with, overriding the issuer's signing algorithm
+const SigningAlgorithmAnnotation = "aws-privateca-issuer/signing-algorithm"
+
+// ErrSigningAlgorithm is returned when the signing algorithm selected for a
+// CertificateRequest is unknown or cannot be used with the key of the CA
+var ErrSigningAlgorithm = errors.New("signing algorithm cannot be used")
+
+// caDescriptionTTL is how long the description of a CA is used for signing
+// before the CA is described again, so that changes to the CA are picked up
+// even when the issuer is not re-verified
+const caDescriptionTTL = 10 * time.Minute
+
+// caDescription caches the description of a provisioner's CA. It is shared by
+// all the CertificateRequests signed by the provisioner.
+type caDescription struct {
+	mu          sync.Mutex
+	ca          *acmpcatypes.CertificateAuthority
+	describedAt time.Time
+}
+
+// ValidateSigningAlgorithm checks that a signing algorithm is known to PCA
+func ValidateSigningAlgorithm(algorithm string) error {
+	if !slices.Contains(acmpcatypes.SigningAlgorithm("").Values(), acmpcatypes.SigningAlgorithm(algorithm)) {
+		return fmt.Errorf("unknown signing algorithm %q", algorithm)
+	}
+	return nil
+}
+
+// certificateAuthority returns the description of the provisioner's CA,
+// describing the CA again once the cached description is older than
+// caDescriptionTTL
+func (p *PCAProvisioner) certificateAuthority(ctx context.Context) (*acmpcatypes.CertificateAuthority, error) {
+	if p.description == nil {
+		return p.describeCertificateAuthority(ctx)
+	}
+
+	p.description.mu.Lock()
+	defer p.description.mu.Unlock()
+	if p.description.ca != nil && p.now().Sub(p.description.describedAt) < caDescriptionTTL {
+		return p.description.ca, nil
+	}
+
+	ca, err := p.describeCertificateAuthority(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.description.ca = ca
+	p.description.describedAt = p.now()
+	return ca, nil
+}
+
+// selectSigningAlgorithm picks the algorithm a CertificateRequest is signed
+// with. An algorithm requested through the annotation wins over the issuer's
+// algorithm, which in turn wins over the CA's default. The algorithm must
+// belong to the family of the CA's key.
+func (p *PCAProvisioner) selectSigningAlgorithm(cr *cmapi.CertificateRequest, ca *acmpcatypes.CertificateAuthority) (acmpcatypes.SigningAlgorithm, error) {
+	algorithm := caConfiguration(ca).SigningAlgorithm
+	if p.signingAlgorithm != "" {
+		algorithm = p.signingAlgorithm
+	}
+	if requested, ok := cr.GetAnnotations()[SigningAlgorithmAnnotation]; ok {
+		if err := ValidateSigningAlgorithm(requested); err != nil {
+			return "", err
+		}
+		algorithm = acmpcatypes.SigningAlgorithm(requested)
+	}
+
+	if err := ValidateSigningAlgorithmForCA(algorithm, ca); err != nil {
+		return "", err
+	}
+	return algorithm, nil
+}
+
+// ValidateSigningAlgorithmForCA checks that a CA can sign with the given
+// algorithm, i.e. that the algorithm belongs to the family of the CA's key
+func ValidateSigningAlgorithmForCA(algorithm acmpcatypes.SigningAlgorithm, ca *acmpcatypes.CertificateAuthority) error {
+	key := caConfiguration(ca).KeyAlgorithm
+	if !signingAlgorithmMatchesKey(algorithm, key) {
+		return fmt.Errorf("signing algorithm %s cannot be used with the %s key of the certificate authority", algorithm, key)
+	}
+	return nil
+}
+
+func caConfiguration(ca *acmpcatypes.CertificateAuthority) acmpcatypes.CertificateAuthorityConfiguration {
+	if ca == nil || ca.CertificateAuthorityConfiguration == nil {
+		return acmpcatypes.CertificateAuthorityConfiguration{}
+	}
+	return *ca.CertificateAuthorityConfiguration
+}
+
+// signingAlgorithmMatchesKey reports whether a CA with a key of the given
+// algorithm can sign with the given signing algorithm. Unknown key algorithms
+// are left for PCA to check.
+func signingAlgorithmMatchesKey(algorithm acmpcatypes.SigningAlgorithm, key acmpcatypes.KeyAlgorithm) bool {
+	switch {
+	case strings.HasPrefix(string(key), "RSA_"):
+		return strings.HasSuffix(string(algorithm), "WITHRSA")
+	case strings.HasPrefix(string(key), "EC_"):
+		return strings.HasSuffix(string(algorithm), "WITHECDSA")
+	case key == acmpcatypes.KeyAlgorithmSm2:
+		return algorithm == acmpcatypes.SigningAlgorithmSm3withsm2
+	default:
+		return true
+	}
+}
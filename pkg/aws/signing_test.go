@@ -0,0 +1,170 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/acmpca"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// describingACMPCAClient describes a CA with the given key and default
+// signing algorithm, and counts the calls to DescribeCertificateAuthority
+type describingACMPCAClient struct {
+	workingACMPCAClient
+	keyAlgorithm     acmpcatypes.KeyAlgorithm
+	signingAlgorithm acmpcatypes.SigningAlgorithm
+	describes        int
+}
+
+func (m *describingACMPCAClient) DescribeCertificateAuthority(_ context.Context, input *acmpca.DescribeCertificateAuthorityInput, _ ...func(*acmpca.Options)) (*acmpca.DescribeCertificateAuthorityOutput, error) {
+	m.describes++
+	return &acmpca.DescribeCertificateAuthorityOutput{
+		CertificateAuthority: &acmpcatypes.CertificateAuthority{
+			CertificateAuthorityConfiguration: &acmpcatypes.CertificateAuthorityConfiguration{
+				KeyAlgorithm:     m.keyAlgorithm,
+				SigningAlgorithm: m.signingAlgorithm,
+			},
+		},
+	}, nil
+}
+
+func signingTestRequest(t *testing.T, annotations map[string]string) *cmapi.CertificateRequest {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	csrBytes, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	require.NoError(t, err)
+
+	return &cmapi.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+		Spec: cmapi.CertificateRequestSpec{
+			Request: pem.EncodeToMemory(&pem.Block{Bytes: csrBytes, Type: "CERTIFICATE REQUEST"}),
+		},
+	}
+}
+
+func TestPCASignSigningAlgorithm(t *testing.T) {
+	type testCase struct {
+		keyAlgorithm      acmpcatypes.KeyAlgorithm
+		issuerAlgorithm   acmpcatypes.SigningAlgorithm
+		annotations       map[string]string
+		expectedAlgorithm acmpcatypes.SigningAlgorithm
+		expectFailure     bool
+	}
+
+	tests := map[string]testCase{
+		"ca-default": {
+			keyAlgorithm:      acmpcatypes.KeyAlgorithmRsa2048,
+			expectedAlgorithm: acmpcatypes.SigningAlgorithmSha256withrsa,
+		},
+		"issuer-algorithm": {
+			keyAlgorithm:      acmpcatypes.KeyAlgorithmRsa4096,
+			issuerAlgorithm:   acmpcatypes.SigningAlgorithmSha512withrsa,
+			expectedAlgorithm: acmpcatypes.SigningAlgorithmSha512withrsa,
+		},
+		"annotation-wins-over-issuer": {
+			keyAlgorithm:      acmpcatypes.KeyAlgorithmRsa2048,
+			issuerAlgorithm:   acmpcatypes.SigningAlgorithmSha512withrsa,
+			annotations:       map[string]string{SigningAlgorithmAnnotation: "SHA384WITHRSA"},
+			expectedAlgorithm: acmpcatypes.SigningAlgorithmSha384withrsa,
+		},
+		"ec-key": {
+			keyAlgorithm:      acmpcatypes.KeyAlgorithmEcSecp384r1,
+			annotations:       map[string]string{SigningAlgorithmAnnotation: "SHA384WITHECDSA"},
+			expectedAlgorithm: acmpcatypes.SigningAlgorithmSha384withecdsa,
+		},
+		"issuer-algorithm-of-other-family": {
+			keyAlgorithm:    acmpcatypes.KeyAlgorithmEcPrime256v1,
+			issuerAlgorithm: acmpcatypes.SigningAlgorithmSha256withrsa,
+			expectFailure:   true,
+		},
+		"annotation-of-other-family": {
+			keyAlgorithm:  acmpcatypes.KeyAlgorithmRsa2048,
+			annotations:   map[string]string{SigningAlgorithmAnnotation: "SHA256WITHECDSA"},
+			expectFailure: true,
+		},
+		"unknown-annotation": {
+			keyAlgorithm:  acmpcatypes.KeyAlgorithmRsa2048,
+			annotations:   map[string]string{SigningAlgorithmAnnotation: "MD5WITHRSA"},
+			expectFailure: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			defaultAlgorithm := acmpcatypes.SigningAlgorithmSha256withrsa
+			if tc.keyAlgorithm != acmpcatypes.KeyAlgorithmRsa2048 && tc.keyAlgorithm != acmpcatypes.KeyAlgorithmRsa4096 {
+				defaultAlgorithm = acmpcatypes.SigningAlgorithmSha256withecdsa
+			}
+			client := &describingACMPCAClient{keyAlgorithm: tc.keyAlgorithm, signingAlgorithm: defaultAlgorithm}
+			provisioner := PCAProvisioner{arn: arn, pcaClient: client, signingAlgorithm: tc.issuerAlgorithm}
+
+			err := provisioner.Sign(context.TODO(), signingTestRequest(t, tc.annotations), logr.Discard())
+			if tc.expectFailure {
+				assert.ErrorIs(t, err, ErrSigningAlgorithm)
+				assert.Nil(t, client.issueCertInput)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedAlgorithm, client.issueCertInput.SigningAlgorithm)
+		})
+	}
+}
+
+func TestCertificateAuthorityDescription(t *testing.T) {
+	now := time.Now()
+	client := &describingACMPCAClient{
+		keyAlgorithm:     acmpcatypes.KeyAlgorithmRsa2048,
+		signingAlgorithm: acmpcatypes.SigningAlgorithmSha256withrsa,
+	}
+	provisioner := PCAProvisioner{
+		arn:         arn,
+		pcaClient:   client,
+		description: &caDescription{},
+		clock:       func() time.Time { return now },
+	}
+	ctx := context.TODO()
+
+	require.NoError(t, provisioner.Sign(ctx, signingTestRequest(t, nil), logr.Discard()))
+	require.NoError(t, provisioner.Sign(ctx, signingTestRequest(t, nil), logr.Discard()))
+	assert.Equal(t, 1, client.describes, "expected the description to be cached")
+
+	// A change to the CA is picked up once the description expires
+	client.signingAlgorithm = acmpcatypes.SigningAlgorithmSha384withrsa
+	now = now.Add(caDescriptionTTL)
+	require.NoError(t, provisioner.Sign(ctx, signingTestRequest(t, nil), logr.Discard()))
+	assert.Equal(t, 2, client.describes)
+	assert.Equal(t, acmpcatypes.SigningAlgorithmSha384withrsa, client.issueCertInput.SigningAlgorithm)
+
+	// Verifying the issuer refreshes the description
+	client.signingAlgorithm = acmpcatypes.SigningAlgorithmSha512withrsa
+	_, err := provisioner.DescribeCertificateAuthority(ctx)
+	require.NoError(t, err)
+	require.NoError(t, provisioner.Sign(ctx, signingTestRequest(t, nil), logr.Discard()))
+	assert.Equal(t, 3, client.describes)
+	assert.Equal(t, acmpcatypes.SigningAlgorithmSha512withrsa, client.issueCertInput.SigningAlgorithm)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"strings"
+
+	awsarn "github.com/aws/aws-sdk-go-v2/aws/arn"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateIssuerSpec checks the CA ARN and its region, the role ARNs, and the
+// parts of the spec that cannot be expressed in the CRD schema. It is run by
+// the validating webhook and by the issuer controller, for issuers admitted
+// without the webhook.
+func ValidateIssuerSpec(name types.NamespacedName, spec *api.AWSPCAIssuerSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if spec.Arn == "" {
+		errs = append(errs, field.Required(path.Child("arn"), "the ARN of the certificate authority is required"))
+	} else if _, err := ParseCertificateAuthorityArn(spec.Arn); err != nil {
+		errs = append(errs, field.Invalid(path.Child("arn"), spec.Arn, err.Error()))
+	} else if err := ValidateRegion(spec); err != nil {
+		errs = append(errs, field.Invalid(path.Child("region"), spec.Region, err.Error()))
+	}
+
+	if spec.Role != "" {
+		errs = append(errs, validateRoleArn(path.Child("role"), spec.Role)...)
+	}
+	for i, hop := range spec.RoleChain {
+		errs = append(errs, validateRoleArn(path.Child("roleChain").Index(i).Child("role"), hop.Role)...)
+	}
+	if spec.RolesAnywhere != nil {
+		errs = append(errs, validateRoleArn(path.Child("rolesAnywhere", "roleArn"), spec.RolesAnywhere.RoleArn)...)
+	}
+
+	if ref := spec.SecretRef; ref.Name == "" && (ref.AccessKeyIDSelector.Key != "" || ref.SecretAccessKeySelector.Key != "" ||
+		ref.SessionTokenSelector.Key != "" || ref.ExpirationSelector.Key != "") {
+		errs = append(errs, field.Required(path.Child("secretRef", "name"), "key selectors require the name of the secret"))
+	}
+
+	if spec.TemplateArn != "" {
+		if err := ValidateTemplateArn(spec.TemplateArn); err != nil {
+			errs = append(errs, field.Invalid(path.Child("templateArn"), spec.TemplateArn, err.Error()))
+		}
+	}
+	for i, template := range spec.AllowedTemplateArns {
+		if err := ValidateTemplateArn(template); err != nil {
+			errs = append(errs, field.Invalid(path.Child("allowedTemplateArns").Index(i), template, err.Error()))
+		}
+	}
+	if err := ValidateAssumeRoleOptions(spec.Role, spec.AssumeRoleOptions); err != nil {
+		errs = append(errs, field.Invalid(path.Child("assumeRoleOptions"), spec.AssumeRoleOptions, err.Error()))
+	}
+	if err := ValidateRoleChain(spec.Role, spec.RoleChain); err != nil {
+		errs = append(errs, field.Invalid(path.Child("roleChain"), spec.RoleChain, err.Error()))
+	}
+	if err := ValidateEndpoints(spec.Endpoints); err != nil {
+		errs = append(errs, field.Invalid(path.Child("endpoints"), spec.Endpoints, err.Error()))
+	}
+	if err := ValidateRolesAnywhere(spec); err != nil {
+		errs = append(errs, field.Invalid(path.Child("rolesAnywhere"), spec.RolesAnywhere, err.Error()))
+	}
+	if err := ValidateCredentialsRef(name, spec); err != nil {
+		errs = append(errs, field.Invalid(path.Child("credentialsRef"), spec.CredentialsRef, err.Error()))
+	}
+	errs = append(errs, validateValidity(spec, path)...)
+	if spec.SigningAlgorithm != "" {
+		if err := ValidateSigningAlgorithm(spec.SigningAlgorithm); err != nil {
+			errs = append(errs, field.Invalid(path.Child("signingAlgorithm"), spec.SigningAlgorithm, err.Error()))
+		}
+	}
+	if err := ValidateIssuancePolicy(spec.IssuancePolicy); err != nil {
+		errs = append(errs, field.Invalid(path.Child("issuancePolicy"), spec.IssuancePolicy, err.Error()))
+	}
+	if err := ValidateApiPassthrough(spec.ApiPassthrough); err != nil {
+		errs = append(errs, field.Invalid(path.Child("apiPassthrough"), spec.ApiPassthrough, err.Error()))
+	}
+
+	return errs
+}
+
+// validateValidity checks that the durations of an issuer are not negative.
+// A defaultDuration longer than maxDuration is shortened to it.
+func validateValidity(spec *api.AWSPCAIssuerSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for _, duration := range []struct {
+		name  string
+		value *metav1.Duration
+	}{
+		{"defaultDuration", spec.DefaultDuration},
+		{"maxDuration", spec.MaxDuration},
+		{"notBeforeBackdate", spec.NotBeforeBackdate},
+	} {
+		if duration.value != nil && duration.value.Duration < 0 {
+			errs = append(errs, field.Invalid(path.Child(duration.name), duration.value.Duration.String(), "must not be negative"))
+		}
+	}
+	return errs
+}
+
+func validateRoleArn(path *field.Path, role string) field.ErrorList {
+	parsed, err := awsarn.Parse(role)
+	if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return field.ErrorList{field.Invalid(path, role, "must be the ARN of an IAM role")}
+	}
+	return nil
+}
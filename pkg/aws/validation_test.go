@@ -0,0 +1,140 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package aws
+
+import (
+	"testing"
+	"time"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestValidateIssuerSpec(t *testing.T) {
+	const roleArn = "arn:aws:iam::123456789012:role/pca"
+
+	type testCase struct {
+		name           types.NamespacedName
+		spec           issuerapi.AWSPCAIssuerSpec
+		expectedFields []string
+	}
+
+	tests := map[string]testCase{
+		"valid": {
+			spec: issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", Role: roleArn},
+		},
+		"no-region": {
+			spec: issuerapi.AWSPCAIssuerSpec{Arn: arn},
+		},
+		"no-arn": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Region: "us-east-1"},
+			expectedFields: []string{"spec.arn"},
+		},
+		"malformed-arn": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: "certificate-authority/12345678"},
+			expectedFields: []string{"spec.arn"},
+		},
+		"not-a-ca-arn": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: roleArn},
+			expectedFields: []string{"spec.arn"},
+		},
+		"region-mismatch": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "eu-west-1"},
+			expectedFields: []string{"spec.region"},
+		},
+		"role-not-an-iam-role": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: arn, Role: "arn:aws:iam::123456789012:user/pca"},
+			expectedFields: []string{"spec.role"},
+		},
+		"role-chain-hop-not-an-arn": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:       arn,
+				RoleChain: []issuerapi.RoleChainHop{{Role: roleArn}, {Role: "pca"}},
+			},
+			expectedFields: []string{"spec.roleChain[1].role"},
+		},
+		"selectors-without-secret": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn: arn,
+				SecretRef: issuerapi.AWSCredentialsSecretReference{
+					AccessKeyIDSelector: v1.SecretKeySelector{Key: "id"},
+				},
+			},
+			expectedFields: []string{"spec.secretRef.name"},
+		},
+		"assume-role-options-without-role": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:               arn,
+				AssumeRoleOptions: &issuerapi.AssumeRoleOptions{ExternalID: "external"},
+			},
+			expectedFields: []string{"spec.assumeRoleOptions"},
+		},
+		"unknown-signing-algorithm": {
+			spec:           issuerapi.AWSPCAIssuerSpec{Arn: arn, SigningAlgorithm: "MD5WITHRSA"},
+			expectedFields: []string{"spec.signingAlgorithm"},
+		},
+		"invalid-issuance-policy": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				IssuancePolicy: &issuerapi.IssuancePolicy{AllowedIPRanges: []string{"10.0.0.1"}},
+			},
+			expectedFields: []string{"spec.issuancePolicy"},
+		},
+		"negative-backdate": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:               arn,
+				NotBeforeBackdate: &metav1.Duration{Duration: -time.Minute},
+			},
+			expectedFields: []string{"spec.notBeforeBackdate"},
+		},
+		"issuer-cluster-credentials": {
+			name: types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.ClusterCredentialsKind},
+			},
+			expectedFields: []string{"spec.credentialsRef"},
+		},
+		"cluster-issuer-cluster-credentials": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.ClusterCredentialsKind},
+			},
+		},
+		"default-duration-over-max-duration": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:             arn,
+				DefaultDuration: &metav1.Duration{Duration: 720 * time.Hour},
+				MaxDuration:     &metav1.Duration{Duration: 24 * time.Hour},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			errs := ValidateIssuerSpec(tc.name, &tc.spec, field.NewPath("spec"))
+
+			var fields []string
+			for _, err := range errs {
+				fields = append(fields, err.Field)
+			}
+			assert.Equal(t, tc.expectedFields, fields)
+		})
+	}
+}
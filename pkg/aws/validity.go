@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+)
+
+// DefaultDuration is the validity of certificates when neither the
+// CertificateRequest nor its issuer specify a duration, e.g. for issuers
+// stored before defaultDuration was defaulted by the CRD
+const DefaultDuration = 30 * 24 * time.Hour
+
+var (
+	// ErrValidityExceedsCA is returned when a certificate would outlive the
+	// certificate of its CA and the issuer's CA expiry policy is Fail
+	ErrValidityExceedsCA = errors.New("certificate would outlive the certificate authority")
+	// ErrInvalidDuration is returned when a CertificateRequest requests a
+	// duration that is not positive
+	ErrInvalidDuration = errors.New("invalid certificate duration")
+)
+
+// validityOptions holds the issuer settings that determine the validity of
+// issued certificates
+type validityOptions struct {
+	defaultDuration   time.Duration
+	maxDuration       time.Duration
+	notBeforeBackdate time.Duration
+	caExpiryPolicy    api.CAExpiryPolicy
+}
+
+func toValidityOptions(spec *api.AWSPCAIssuerSpec) validityOptions {
+	options := validityOptions{caExpiryPolicy: spec.CAExpiryPolicy}
+	if spec.DefaultDuration != nil {
+		options.defaultDuration = spec.DefaultDuration.Duration
+	}
+	if spec.MaxDuration != nil {
+		options.maxDuration = spec.MaxDuration.Duration
+	}
+	if spec.NotBeforeBackdate != nil {
+		options.notBeforeBackdate = spec.NotBeforeBackdate.Duration
+	}
+	return options
+}
+
+// duration returns the duration of the certificate issued for a
+// CertificateRequest before it is clamped to its CA, and why it is shorter
+// than requested if it is. The duration requested by the CertificateRequest,
+// or else the issuer's default duration or DefaultDuration, is shortened to
+// the issuer's maximum duration.
+func (o validityOptions) duration(cr *cmapi.CertificateRequest) (duration time.Duration, shortened string, err error) {
+	duration = DefaultDuration
+	if o.defaultDuration > 0 {
+		duration = o.defaultDuration
+	}
+	if cr.Spec.Duration != nil {
+		duration = cr.Spec.Duration.Duration
+	}
+	if duration <= 0 {
+		return 0, "", fmt.Errorf("%w: the requested duration %s is not positive", ErrInvalidDuration, duration)
+	}
+	if o.maxDuration > 0 && duration > o.maxDuration {
+		if cr.Spec.Duration != nil {
+			shortened = fmt.Sprintf("the requested duration %s was shortened to the issuer's maxDuration of %s", duration, o.maxDuration)
+		}
+		duration = o.maxDuration
+	}
+	return duration, shortened, nil
+}
+
+// validity returns the start and end of the validity of the certificate
+// issued for a CertificateRequest, and why it is shorter than requested if it
+// is. The duration of the certificate is counted from now, so that backdating
+// NotBefore does not shorten the certificate. A certificate that would
+// outlive the certificate of the CA is either clamped to it or rejected.
+func (o validityOptions) validity(now time.Time, cr *cmapi.CertificateRequest, ca *acmpcatypes.CertificateAuthority) (notBefore, notAfter time.Time, shortened string, err error) {
+	duration, shortened, err := o.duration(cr)
+	if err != nil {
+		return time.Time{}, time.Time{}, "", err
+	}
+
+	notBefore = now.Add(-o.notBeforeBackdate)
+	notAfter = now.Add(duration)
+
+	if ca != nil && ca.NotBefore != nil && notBefore.Before(*ca.NotBefore) {
+		notBefore = *ca.NotBefore
+	}
+	if ca != nil && ca.NotAfter != nil && notAfter.After(*ca.NotAfter) {
+		if o.caExpiryPolicy != api.CAExpiryPolicyClamp {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("%w: the certificate would expire at %s but the certificate authority expires at %s",
+				ErrValidityExceedsCA, notAfter.UTC().Format(time.RFC3339), ca.NotAfter.UTC().Format(time.RFC3339))
+		}
+		notAfter = *ca.NotAfter
+		if !notAfter.After(now) {
+			return time.Time{}, time.Time{}, "", fmt.Errorf("%w: the certificate authority expired at %s",
+				ErrValidityExceedsCA, ca.NotAfter.UTC().Format(time.RFC3339))
+		}
+		shortened = fmt.Sprintf("the validity was shortened to end when the certificate authority expires at %s", ca.NotAfter.UTC().Format(time.RFC3339))
+	}
+
+	return notBefore, notAfter, shortened, nil
+}
@@ -20,9 +20,13 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
 )
@@ -33,11 +37,16 @@ type AWSPCAIssuerReconciler struct {
 	Log               logr.Logger
 	Scheme            *runtime.Scheme
 	GenericController *GenericIssuerReconciler
+
+	// MaxConcurrentReconciles is how many AWSPCAIssuers are reconciled at once.
+	// Zero reconciles one at a time.
+	MaxConcurrentReconciles int
 }
 
 // +kubebuilder:rbac:groups=awspca.cert-manager.io,resources=awspcaissuers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=awspca.cert-manager.io,resources=awspcaissuers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=awspca.cert-manager.io,resources=awspcaissuers/finalizers,verbs=update
+// +kubebuilder:rbac:groups=awspca.cert-manager.io,resources=awspcacredentials;awspcaclustercredentials,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
@@ -59,7 +68,30 @@ func (r *AWSPCAIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AWSPCAIssuerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &api.AWSPCAIssuer{}, secretRefIndexField, indexIssuerSecretRef); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &api.AWSPCAIssuer{}, credentialsRefIndexField, indexIssuerCredentialsRef); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&api.AWSPCAIssuer{}).
+		WatchesMetadata(&core.Secret{}, handler.EnqueueRequestsFromMapFunc(r.issuersForSecret)).
+		Watches(&api.AWSPCACredentials{}, handler.EnqueueRequestsFromMapFunc(r.issuersForCredentials(api.CredentialsKind))).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
+
+// issuersForSecret re-reconciles the AWSPCAIssuers that take their credentials from secret
+func (r *AWSPCAIssuerReconciler) issuersForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	return issuersForSecret(ctx, r.Client, &api.AWSPCAIssuerList{}, secret)
+}
+
+// issuersForCredentials re-reconciles the AWSPCAIssuers that take their credentials from
+// shared credentials of the given kind
+func (r *AWSPCAIssuerReconciler) issuersForCredentials(kind string) handler.MapFunc {
+	return func(ctx context.Context, credentials client.Object) []reconcile.Request {
+		return issuersForCredentials(ctx, r.Client, &api.AWSPCAIssuerList{}, kind, credentials)
+	}
+}
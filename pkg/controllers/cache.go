@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	core "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+)
+
+// CacheOptions keeps the manager's cache small. Managed fields are dropped
+// from every object, and CertificateRequests for other issuers are stripped
+// of their CSR and certificates, which are never read.
+func CacheOptions() cache.Options {
+	return cache.Options{
+		DefaultTransform: cache.TransformStripManagedFields(),
+		ByObject: map[client.Object]cache.ByObject{
+			&cmapi.CertificateRequest{}: {Transform: stripCertificateRequest},
+		},
+	}
+}
+
+// ClientOptions reads Secrets straight from the API server, so that the
+// Secrets of the cluster are not cached. Controllers only watch the metadata
+// of Secrets.
+func ClientOptions() client.Options {
+	return client.Options{
+		Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&core.Secret{}},
+		},
+	}
+}
+
+// stripCertificateRequest drops the managed fields of a CertificateRequest,
+// and its CSR and certificates if it is for another issuer
+func stripCertificateRequest(obj any) (any, error) {
+	cr, ok := obj.(*cmapi.CertificateRequest)
+	if !ok {
+		return obj, nil
+	}
+
+	// Nil managed fields are left alone, see https://github.com/kubernetes/kubernetes/issues/124337
+	if cr.GetManagedFields() != nil {
+		cr.SetManagedFields(nil)
+	}
+	if cr.Spec.IssuerRef.Group != api.GroupVersion.Group {
+		cr.Spec.Request = nil
+		cr.Status.Certificate = nil
+		cr.Status.CA = nil
+	}
+	return cr, nil
+}
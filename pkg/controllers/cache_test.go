@@ -0,0 +1,83 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"testing"
+
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmgen "github.com/cert-manager/cert-manager/test/unit/gen"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+)
+
+func TestStripCertificateRequest(t *testing.T) {
+	type testCase struct {
+		group           string
+		expectedPayload bool
+	}
+
+	tests := map[string]testCase{
+		"our-issuer": {
+			group:           issuerapi.GroupVersion.Group,
+			expectedPayload: true,
+		},
+		"other-issuer": {
+			group:           "cert-manager.io",
+			expectedPayload: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := cmgen.CertificateRequest(
+				"cr1",
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer1", Group: tc.group, Kind: "Issuer"}),
+				cmgen.SetCertificateRequestCSR([]byte("csr")),
+				cmgen.SetCertificateRequestCertificate([]byte("cert")),
+				cmgen.SetCertificateRequestCA([]byte("ca")),
+			)
+			cr.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "cert-manager"}})
+
+			obj, err := stripCertificateRequest(cr)
+			require.NoError(t, err)
+			stripped := obj.(*cmapi.CertificateRequest)
+			assert.Nil(t, stripped.GetManagedFields())
+			assert.Equal(t, "issuer1", stripped.Spec.IssuerRef.Name)
+			if tc.expectedPayload {
+				assert.Equal(t, []byte("csr"), stripped.Spec.Request)
+				assert.Equal(t, []byte("cert"), stripped.Status.Certificate)
+				assert.Equal(t, []byte("ca"), stripped.Status.CA)
+			} else {
+				assert.Nil(t, stripped.Spec.Request)
+				assert.Nil(t, stripped.Status.Certificate)
+				assert.Nil(t, stripped.Status.CA)
+			}
+		})
+	}
+}
+
+func TestStripCertificateRequestIgnoresOtherObjects(t *testing.T) {
+	secret := &v1.Secret{Data: map[string][]byte{"key": []byte("value")}}
+
+	obj, err := stripCertificateRequest(secret)
+	require.NoError(t, err)
+	assert.Equal(t, secret, obj)
+}
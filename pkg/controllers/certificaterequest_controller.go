@@ -20,11 +20,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
 	awspca "github.com/cert-manager/aws-privateca-issuer/pkg/aws"
 	"github.com/cert-manager/aws-privateca-issuer/pkg/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -33,9 +36,15 @@ import (
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
 	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
@@ -51,15 +60,50 @@ type CertificateRequestReconciler struct {
 
 	Clock                  clock.Clock
 	CheckApprovedCondition bool
+	// IssuanceTimeout is how long to wait for PCA to issue a certificate
+	// before failing its CertificateRequest. Zero waits forever.
+	IssuanceTimeout time.Duration
+	// MaxConcurrentReconciles is how many CertificateRequests are reconciled
+	// at once. Zero reconciles one at a time.
+	MaxConcurrentReconciles int
 }
 
+const (
+	// RevokeAnnotation requests revocation of the certificate of a CertificateRequest
+	// whose issuer has the OnAnnotation revocation policy
+	RevokeAnnotation = "aws-privateca-issuer/revoke"
+	// RevokedAtAnnotation records when the certificate of a CertificateRequest was revoked
+	RevokedAtAnnotation = "aws-privateca-issuer/revoked-at"
+
+	// IssuanceStartedAtAnnotation records when the certificate of a CertificateRequest was requested from PCA
+	IssuanceStartedAtAnnotation = "aws-privateca-issuer/issuance-started-at"
+	// IssuanceAttemptsAnnotation counts the attempts to retrieve the certificate
+	// of a CertificateRequest that PCA was still issuing
+	IssuanceAttemptsAnnotation = "aws-privateca-issuer/issuance-attempts"
+
+	revocationFinalizer = "awspca.cert-manager.io/revoke-certificate"
+)
+
+// The wait before retrieving a certificate from PCA is as long as the time
+// since it was requested, so that it doubles with every attempt that finds it
+// still issuing, from issuanceInitialBackoff up to issuanceMaxBackoff. It is
+// spread by up to issuanceBackoffJitter so that requests signed together are
+// not retrieved in lockstep.
+const (
+	issuanceInitialBackoff = time.Second
+	issuanceMaxBackoff     = time.Minute
+	issuanceBackoffJitter  = 0.2
+)
+
 // We put this in a variable to easily mock it
 var (
 	GetProvisioner = awspca.GetProvisioner
+	NewProvisioner = awspca.NewProvisioner
 )
 
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
@@ -85,6 +129,14 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	if handled, err := r.reconcileRevocation(ctx, cr, log); handled || err != nil {
+		if requeueAfter, limited := rateLimited(err); limited {
+			log.V(1).Info("rate limit of PCA reached, requeuing", "requeueAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
 	// Ignore CertificateRequest if it is already Ready
 	if cmutil.CertificateRequestHasCondition(cr, cmapi.CertificateRequestCondition{
 		Type:   cmapi.CertificateRequestConditionReady,
@@ -139,14 +191,7 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, nil
 	}
 
-	issuerName := types.NamespacedName{
-		Namespace: cr.Namespace,
-		Name:      cr.Spec.IssuerRef.Name,
-	}
-	if cr.Spec.IssuerRef.Kind == "AWSPCAClusterIssuer" {
-		issuerName.Namespace = ""
-	}
-
+	issuerName := issuerNameForRequest(cr)
 	iss, err := util.GetIssuer(ctx, r.Client, issuerName)
 	if err != nil {
 		log.Error(err, "failed to retrieve Issuer resource")
@@ -163,45 +208,328 @@ func (r *CertificateRequestReconciler) Reconcile(ctx context.Context, req ctrl.R
 	provisioner, err := GetProvisioner(ctx, r.Client, issuerName, iss.GetSpec())
 	if err != nil {
 		log.Error(err, "failed to retrieve provisioner")
-		_ = r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "failed to retrieve provisioner")
+		reason := cmapi.CertificateRequestReasonPending
+		if awspca.IsPermanentError(err) {
+			reason = cmapi.CertificateRequestReasonFailed
+		}
+		_ = r.setStatus(ctx, cr, cmmeta.ConditionFalse, reason, "failed to retrieve provisioner")
 		return ctrl.Result{}, err
 	}
 
-	certArn, exists := cr.GetAnnotations()["aws-privateca-issuer/certificate-arn"]
+	certArn, exists := cr.GetAnnotations()[awspca.CertificateArnAnnotation]
 	if !exists {
 		err := provisioner.Sign(ctx, cr, log)
-		if err != nil {
+		if requeueAfter, limited := rateLimited(err); limited {
+			log.V(1).Info("rate limit of PCA reached, requeuing", "requeueAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		if err != nil && awspca.IsPermanentError(err) {
 			log.Error(err, "failed to request certificate from PCA")
-			return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "failed to request certificate from PCA: "+err.Error())
+			return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "failed to request certificate from PCA: %s", err.Error())
+		}
+		if err != nil {
+			// Returning the error lets the workqueue retry with its rate limited backoff
+			log.Error(err, "failed to request certificate from PCA, will retry")
+			_ = r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "failed to request certificate from PCA, will retry: %s", err.Error())
+			return ctrl.Result{}, err
 		}
 
-		return ctrl.Result{Requeue: true}, r.Update(ctx, cr)
+		if shortened := cr.GetAnnotations()[awspca.ValidityShortenedAnnotation]; shortened != "" {
+			r.Recorder.Event(cr, core.EventTypeWarning, "ValidityShortened", shortened)
+		}
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, IssuanceStartedAtAnnotation, r.Clock.Now().UTC().Format(time.RFC3339))
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, IssuanceAttemptsAnnotation, "0")
+		return ctrl.Result{RequeueAfter: issuanceBackoff(0)}, r.Update(ctx, cr)
 	}
 
 	pem, ca, err := provisioner.Get(ctx, cr, certArn, log)
+	if requeueAfter, limited := rateLimited(err); limited {
+		log.V(1).Info("rate limit of PCA reached, requeuing", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 	if err != nil {
 		var errorType *acmpcatypes.RequestInProgressException
 		if errors.As(err, &errorType) {
-			log.Info("certificate is still issuing")
-			return ctrl.Result{Requeue: true}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "waiting for certificate to be issued")
+			return r.waitForIssuance(ctx, cr, certArn, "waiting for certificate to be issued", log)
+		}
+
+		if !awspca.IsPermanentError(err) {
+			log.Error(err, "failed to retrieve certificate from PCA, will retry")
+			return r.waitForIssuance(ctx, cr, certArn, "failed to retrieve certificate from PCA, will retry: "+err.Error(), log)
 		}
 
 		log.Error(err, "failed to issue certificate from PCA")
-		return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "failed to issue certificate from PCA: "+err.Error())
+		return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed, "failed to issue certificate from PCA: %s", err.Error())
 	}
 
 	cr.Status.Certificate = pem
 	cr.Status.CA = ca
+	if shortened := cr.GetAnnotations()[awspca.ValidityShortenedAnnotation]; shortened != "" {
+		return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued, "certificate issued, %s", shortened)
+	}
 	return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued, "certificate issued")
 }
 
+// waitForIssuance schedules the next attempt to retrieve a certificate that PCA
+// is still issuing, or that could not be retrieved because of a retryable
+// error, and reports message as the Pending reason. It fails the
+// CertificateRequest once the issuance timeout has passed. Each attempt is
+// counted in the annotations of the CertificateRequest, and its Ready
+// condition is only written when it changes. certificateRequestPredicate
+// ignores both writes, so that polling does not trigger reconciliations of its
+// own.
+func (r *CertificateRequestReconciler) waitForIssuance(ctx context.Context, cr *cmapi.CertificateRequest, certArn, message string, log logr.Logger) (ctrl.Result, error) {
+	now := r.Clock.Now()
+	startedAt, err := time.Parse(time.RFC3339, cr.GetAnnotations()[IssuanceStartedAtAnnotation])
+	if err != nil {
+		// Certificates requested before issuance was tracked are timed from now
+		startedAt = now
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, IssuanceStartedAtAnnotation, now.UTC().Format(time.RFC3339))
+	}
+
+	elapsed := now.Sub(startedAt)
+	if r.IssuanceTimeout > 0 && elapsed >= r.IssuanceTimeout {
+		log.Info("certificate was not issued in time", "arn", certArn, "startedAt", startedAt)
+		return ctrl.Result{}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed,
+			"certificate %s was not issued by PCA within %s", certArn, r.IssuanceTimeout)
+	}
+
+	attempts, _ := strconv.Atoi(cr.GetAnnotations()[IssuanceAttemptsAnnotation])
+	attempts++
+	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, IssuanceAttemptsAnnotation, strconv.Itoa(attempts))
+	if err := r.Update(ctx, cr); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	requeueAfter := issuanceBackoff(elapsed)
+	log.Info("certificate is not retrieved yet", "startedAt", startedAt, "attempts", attempts, "requeueAfter", requeueAfter)
+	condition := cmutil.GetCertificateRequestCondition(cr, cmapi.CertificateRequestConditionReady)
+	if condition != nil && condition.Status == cmmeta.ConditionFalse && condition.Reason == cmapi.CertificateRequestReasonPending && condition.Message == message {
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, r.setStatus(ctx, cr, cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending, "%s", message)
+}
+
+// rateLimited reports whether a call to PCA was held back by the rate limit,
+// and when it may be made
+func rateLimited(err error) (time.Duration, bool) {
+	var rateLimitedErr *awspca.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return rateLimitedErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// issuanceBackoff returns how long to wait before retrieving a certificate
+// that was requested elapsed ago
+func issuanceBackoff(elapsed time.Duration) time.Duration {
+	backoff := min(max(elapsed, issuanceInitialBackoff), issuanceMaxBackoff)
+	return wait.Jitter(backoff, issuanceBackoffJitter)
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *CertificateRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&cmapi.CertificateRequest{}).
+		For(&cmapi.CertificateRequest{}, builder.WithPredicates(certificateRequestPredicate())).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
+// certificateRequestPredicate only lets through the events of
+// CertificateRequests for our issuers that may need work. Requests that are
+// Ready, Failed or Denied are filtered out, unless they are being deleted or
+// their certificate is to be revoked. Updates made by the controller itself to
+// track issuance are filtered out too, so that polling PCA is paced by the
+// issuance backoff rather than by the watch.
+func certificateRequestPredicate() predicate.Predicate {
+	needsWork := func(obj client.Object) bool {
+		cr, ok := obj.(*cmapi.CertificateRequest)
+		if !ok || cr.Spec.IssuerRef.Group != api.GroupVersion.Group {
+			return false
+		}
+		if !cr.DeletionTimestamp.IsZero() {
+			return true
+		}
+		if _, revoked := cr.GetAnnotations()[RevokedAtAnnotation]; cr.GetAnnotations()[RevokeAnnotation] == "true" && !revoked {
+			return true
+		}
+		return !isTerminal(cr)
+	}
+
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return needsWork(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return needsWork(e.ObjectNew) && !isIssuanceTrackingUpdate(e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return needsWork(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return needsWork(e.Object)
+		},
+	}
+}
+
+// isIssuanceTrackingUpdate reports whether an update of a CertificateRequest
+// only changed what the controller records while a certificate is issued: the
+// certificate ARN, validity, issuance start and issuance attempts annotations,
+// and the Ready condition
+func isIssuanceTrackingUpdate(oldObj, newObj client.Object) bool {
+	oldCR, ok := oldObj.(*cmapi.CertificateRequest)
+	if !ok {
+		return false
+	}
+	newCR, ok := newObj.(*cmapi.CertificateRequest)
+	if !ok {
+		return false
+	}
+	return equality.Semantic.DeepEqual(withoutIssuanceTracking(oldCR), withoutIssuanceTracking(newCR))
+}
+
+// withoutIssuanceTracking returns a copy of a CertificateRequest without the
+// fields compared by isIssuanceTrackingUpdate
+func withoutIssuanceTracking(cr *cmapi.CertificateRequest) *cmapi.CertificateRequest {
+	cr = cr.DeepCopy()
+	cr.ResourceVersion = ""
+	cr.ManagedFields = nil
+	delete(cr.Annotations, awspca.CertificateArnAnnotation)
+	delete(cr.Annotations, awspca.ValidityShortenedAnnotation)
+	delete(cr.Annotations, IssuanceStartedAtAnnotation)
+	delete(cr.Annotations, IssuanceAttemptsAnnotation)
+	if len(cr.Annotations) == 0 {
+		cr.Annotations = nil
+	}
+
+	var conditions []cmapi.CertificateRequestCondition
+	for _, condition := range cr.Status.Conditions {
+		if condition.Type != cmapi.CertificateRequestConditionReady {
+			conditions = append(conditions, condition)
+		}
+	}
+	cr.Status.Conditions = conditions
+	return cr
+}
+
+// isTerminal reports whether a CertificateRequest has been issued, has failed
+// or has been denied
+func isTerminal(cr *cmapi.CertificateRequest) bool {
+	condition := cmutil.GetCertificateRequestCondition(cr, cmapi.CertificateRequestConditionReady)
+	if condition == nil {
+		return false
+	}
+	switch {
+	case condition.Status == cmmeta.ConditionTrue:
+		return true
+	case condition.Status == cmmeta.ConditionFalse:
+		return condition.Reason == cmapi.CertificateRequestReasonFailed || condition.Reason == cmapi.CertificateRequestReasonDenied
+	default:
+		return false
+	}
+}
+
+// reconcileRevocation revokes the certificate of a CertificateRequest when its
+// issuer's revocation policy requires it, and manages the finalizer that keeps
+// a deleted CertificateRequest around until its certificate has been revoked.
+// It returns true when the CertificateRequest needs no further reconciliation.
+func (r *CertificateRequestReconciler) reconcileRevocation(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) (bool, error) {
+	deleting := !cr.DeletionTimestamp.IsZero()
+	certArn, issued := cr.GetAnnotations()[awspca.CertificateArnAnnotation]
+	if !issued {
+		if deleting && controllerutil.RemoveFinalizer(cr, revocationFinalizer) {
+			return true, r.Update(ctx, cr)
+		}
+		return deleting, nil
+	}
+
+	iss, err := util.GetIssuer(ctx, r.Client, issuerNameForRequest(cr))
+	if err != nil {
+		if !deleting {
+			// The issuer lookup is reported by the rest of the reconciliation
+			return false, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return true, err
+		}
+		log.Info("issuer no longer exists, the certificate will not be revoked")
+		if controllerutil.RemoveFinalizer(cr, revocationFinalizer) {
+			return true, r.Update(ctx, cr)
+		}
+		return true, nil
+	}
+
+	policy := iss.GetSpec().RevocationPolicy
+	revokeRequested := cr.GetAnnotations()[RevokeAnnotation] == "true"
+	_, revoked := cr.GetAnnotations()[RevokedAtAnnotation]
+
+	switch {
+	case deleting:
+		if !controllerutil.ContainsFinalizer(cr, revocationFinalizer) {
+			return true, nil
+		}
+		// Only certificates that made it into the CertificateRequest are revoked, so
+		// that a request which failed to issue cannot block its own deletion
+		revokeOnDelete := policy == api.RevocationPolicyOnCertificateRequestDelete ||
+			(policy == api.RevocationPolicyOnAnnotation && revokeRequested)
+		if revokeOnDelete && !revoked && len(cr.Status.Certificate) > 0 {
+			if err := r.revoke(ctx, cr, iss, certArn, log); err != nil {
+				return true, err
+			}
+		}
+		controllerutil.RemoveFinalizer(cr, revocationFinalizer)
+		return true, r.Update(ctx, cr)
+	case policy == "" || policy == api.RevocationPolicyNever:
+		if controllerutil.RemoveFinalizer(cr, revocationFinalizer) {
+			return false, r.Update(ctx, cr)
+		}
+	case policy == api.RevocationPolicyOnAnnotation && revokeRequested && !revoked:
+		if err := r.revoke(ctx, cr, iss, certArn, log); err != nil {
+			return true, err
+		}
+		metav1.SetMetaDataAnnotation(&cr.ObjectMeta, RevokedAtAnnotation, r.Clock.Now().UTC().Format(time.RFC3339))
+		controllerutil.RemoveFinalizer(cr, revocationFinalizer)
+		return true, r.Update(ctx, cr)
+	case !revoked && controllerutil.AddFinalizer(cr, revocationFinalizer):
+		return false, r.Update(ctx, cr)
+	}
+
+	return false, nil
+}
+
+func (r *CertificateRequestReconciler) revoke(ctx context.Context, cr *cmapi.CertificateRequest, iss api.GenericIssuer, certArn string, log logr.Logger) error {
+	provisioner, err := GetProvisioner(ctx, r.Client, issuerNameForRequest(cr), iss.GetSpec())
+	if err != nil {
+		log.Error(err, "failed to retrieve provisioner")
+		return err
+	}
+
+	reason := acmpcatypes.RevocationReason(iss.GetSpec().RevocationReason)
+	if err := provisioner.Revoke(ctx, cr, certArn, reason, log); err != nil {
+		if _, limited := rateLimited(err); limited {
+			return err
+		}
+		log.Error(err, "failed to revoke certificate")
+		r.Recorder.Event(cr, core.EventTypeWarning, "RevocationFailed", "failed to revoke certificate: "+err.Error())
+		return err
+	}
+
+	r.Recorder.Event(cr, core.EventTypeNormal, "Revoked", "certificate revoked")
+	return nil
+}
+
+// issuerNameForRequest returns the name of the issuer referenced by a CertificateRequest
+func issuerNameForRequest(cr *cmapi.CertificateRequest) types.NamespacedName {
+	issuerName := types.NamespacedName{
+		Namespace: cr.Namespace,
+		Name:      cr.Spec.IssuerRef.Name,
+	}
+	if cr.Spec.IssuerRef.Kind == "AWSPCAClusterIssuer" {
+		issuerName.Namespace = ""
+	}
+	return issuerName
+}
+
 func isReady(issuer api.GenericIssuer) bool {
 	for _, condition := range issuer.GetStatus().Conditions {
 		if condition.Type == api.ConditionTypeReady && condition.Status == metav1.ConditionTrue {
@@ -18,9 +18,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
 	cmutil "github.com/cert-manager/cert-manager/pkg/api/util"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
@@ -30,14 +36,20 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
@@ -45,10 +57,14 @@ import (
 )
 
 type fakeProvisioner struct {
-	cert    []byte
-	caCert  []byte
-	getErr  error
-	signErr error
+	cert        []byte
+	caCert      []byte
+	getErr      error
+	signErr     error
+	revokeErr   error
+	revoked     []acmpcatypes.RevocationReason
+	ca          *acmpcatypes.CertificateAuthority
+	describeErr error
 }
 
 func (p *fakeProvisioner) Sign(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) error {
@@ -60,12 +76,36 @@ func (p *fakeProvisioner) Get(ctx context.Context, cr *cmapi.CertificateRequest,
 	return p.cert, p.caCert, p.getErr
 }
 
+func (p *fakeProvisioner) Revoke(ctx context.Context, cr *cmapi.CertificateRequest, certArn string, reason acmpcatypes.RevocationReason, log logr.Logger) error {
+	if p.revokeErr != nil {
+		return p.revokeErr
+	}
+	p.revoked = append(p.revoked, reason)
+	return nil
+}
+
+func (p *fakeProvisioner) DescribeCertificateAuthority(ctx context.Context) (*acmpcatypes.CertificateAuthority, error) {
+	if p.describeErr != nil {
+		return nil, p.describeErr
+	}
+	if p.ca != nil {
+		return p.ca, nil
+	}
+	return &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusActive}, nil
+}
+
 func generateMockGetProvisioner(p *fakeProvisioner, err error) func(context.Context, client.Client, types.NamespacedName, *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
 	return func(_ context.Context, _ client.Client, name types.NamespacedName, _ *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
 		return p, err
 	}
 }
 
+func generateMockNewProvisioner(p *fakeProvisioner, err error) func(aws.Config, types.NamespacedName, *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
+	return func(aws.Config, types.NamespacedName, *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
+		return p, err
+	}
+}
+
 func TestCertificateRequestReconcile(t *testing.T) {
 	type testCase struct {
 		name                         types.NamespacedName
@@ -131,7 +171,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 					},
 				},
 			},
-			expectedSignResult:           ctrl.Result{Requeue: true},
+			expectedSignResult:           ctrl.Result{RequeueAfter: issuanceInitialBackoff},
 			expectedGetResult:            ctrl.Result{},
 			expectedReadyConditionStatus: cmmeta.ConditionTrue,
 			expectedReadyConditionReason: cmapi.CertificateRequestReasonIssued,
@@ -188,7 +228,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 					},
 				},
 			},
-			expectedSignResult:           ctrl.Result{Requeue: true},
+			expectedSignResult:           ctrl.Result{RequeueAfter: issuanceInitialBackoff},
 			expectedGetResult:            ctrl.Result{},
 			expectedReadyConditionStatus: cmmeta.ConditionTrue,
 			expectedReadyConditionReason: cmapi.CertificateRequestReasonIssued,
@@ -255,7 +295,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 			expectedCACertificate:        []byte("oldCaCert"),
 			mockProvisioner:              generateMockGetProvisioner(&fakeProvisioner{caCert: []byte("oldCaCert"), cert: []byte("oldCert")}, nil),
 		},
-		"failure-aws-config-failure": {
+		"pending-aws-config-failure": {
 			name: types.NamespacedName{Namespace: "ns1", Name: "cr1"},
 			objects: []client.Object{
 				cmgen.CertificateRequest(
@@ -297,7 +337,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 				},
 			},
 			expectedReadyConditionStatus: cmmeta.ConditionFalse,
-			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
 			expectedError:                true,
 			mockProvisioner:              generateMockGetProvisioner(nil, fmt.Errorf("Error getting provisioner")),
 		},
@@ -352,8 +392,8 @@ func TestCertificateRequestReconcile(t *testing.T) {
 					},
 				},
 			},
-			expectedSignResult:           ctrl.Result{Requeue: true},
-			expectedGetResult:            ctrl.Result{Requeue: true},
+			expectedSignResult:           ctrl.Result{RequeueAfter: issuanceInitialBackoff},
+			expectedGetResult:            ctrl.Result{RequeueAfter: issuanceInitialBackoff},
 			expectedReadyConditionStatus: cmmeta.ConditionFalse,
 			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
 			expectedError:                false,
@@ -410,11 +450,11 @@ func TestCertificateRequestReconcile(t *testing.T) {
 					},
 				},
 			},
-			expectedSignResult:           ctrl.Result{Requeue: true},
+			expectedSignResult:           ctrl.Result{RequeueAfter: issuanceInitialBackoff},
 			expectedReadyConditionStatus: cmmeta.ConditionFalse,
 			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
 			expectedError:                false,
-			mockProvisioner:              generateMockGetProvisioner(&fakeProvisioner{getErr: errors.New("Get failure")}, nil),
+			mockProvisioner:              generateMockGetProvisioner(&fakeProvisioner{getErr: &acmpcatypes.ResourceNotFoundException{}}, nil),
 		},
 		"pending-issuer-not-ready": {
 			name: types.NamespacedName{Namespace: "ns1", Name: "cr1"},
@@ -556,7 +596,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 			expectedReadyConditionStatus: cmmeta.ConditionFalse,
 			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
 			expectedError:                false,
-			mockProvisioner:              generateMockGetProvisioner(&fakeProvisioner{signErr: errors.New("Sign Failure")}, nil),
+			mockProvisioner:              generateMockGetProvisioner(&fakeProvisioner{signErr: &acmpcatypes.MalformedCSRException{}}, nil),
 		},
 	}
 
@@ -577,6 +617,7 @@ func TestCertificateRequestReconcile(t *testing.T) {
 				Log:      logrtesting.NewTestLogger(t),
 				Scheme:   scheme,
 				Recorder: record.NewFakeRecorder(10),
+				Clock:    clock.RealClock{},
 			}
 
 			ctx := context.TODO()
@@ -586,10 +627,10 @@ func TestCertificateRequestReconcile(t *testing.T) {
 			}
 
 			result, signErr := controller.Reconcile(ctx, reconcile.Request{NamespacedName: tc.name})
-			assert.Equal(t, tc.expectedSignResult, result, "Unexpected sign result")
+			assertRequeue(t, tc.expectedSignResult, result, "Unexpected sign result")
 
 			result, getErr := controller.Reconcile(ctx, reconcile.Request{NamespacedName: tc.name})
-			assert.Equal(t, tc.expectedGetResult, result, "Unexpected get result")
+			assertRequeue(t, tc.expectedGetResult, result, "Unexpected get result")
 
 			if tc.expectedError && (signErr == nil && getErr == nil) {
 				assert.Fail(t, "Expected an error but got none")
@@ -615,6 +656,911 @@ func TestCertificateRequestReconcile(t *testing.T) {
 	}
 }
 
+func TestCertificateRequestRevocation(t *testing.T) {
+	type testCase struct {
+		policy            issuerapi.RevocationPolicy
+		annotations       map[string]string
+		finalizers        []string
+		deleting          bool
+		noIssuer          bool
+		provisioner       *fakeProvisioner
+		expectedError     bool
+		expectedRevoked   []acmpcatypes.RevocationReason
+		expectedFinalizer bool
+		expectedDeleted   bool
+		expectedRevokedAt bool
+		expectedResult    ctrl.Result
+	}
+
+	tests := map[string]testCase{
+		"adds-finalizer-on-delete-policy": {
+			policy:            issuerapi.RevocationPolicyOnCertificateRequestDelete,
+			provisioner:       &fakeProvisioner{},
+			expectedFinalizer: true,
+		},
+		"no-finalizer-without-policy": {
+			provisioner: &fakeProvisioner{},
+		},
+		"removes-finalizer-when-policy-is-never": {
+			policy:      issuerapi.RevocationPolicyNever,
+			finalizers:  []string{revocationFinalizer},
+			provisioner: &fakeProvisioner{},
+		},
+		"revokes-on-delete": {
+			policy:          issuerapi.RevocationPolicyOnCertificateRequestDelete,
+			finalizers:      []string{revocationFinalizer},
+			deleting:        true,
+			provisioner:     &fakeProvisioner{},
+			expectedRevoked: []acmpcatypes.RevocationReason{acmpcatypes.RevocationReasonSuperseded},
+			expectedDeleted: true,
+		},
+		"annotation-policy-does-not-revoke-on-delete": {
+			policy:          issuerapi.RevocationPolicyOnAnnotation,
+			finalizers:      []string{revocationFinalizer},
+			deleting:        true,
+			provisioner:     &fakeProvisioner{},
+			expectedDeleted: true,
+		},
+		"revokes-on-annotation": {
+			policy:            issuerapi.RevocationPolicyOnAnnotation,
+			annotations:       map[string]string{RevokeAnnotation: "true"},
+			finalizers:        []string{revocationFinalizer},
+			provisioner:       &fakeProvisioner{},
+			expectedRevoked:   []acmpcatypes.RevocationReason{acmpcatypes.RevocationReasonSuperseded},
+			expectedRevokedAt: true,
+		},
+		"already-revoked-on-annotation": {
+			policy:            issuerapi.RevocationPolicyOnAnnotation,
+			annotations:       map[string]string{RevokeAnnotation: "true", RevokedAtAnnotation: "2021-01-01T00:00:00Z"},
+			provisioner:       &fakeProvisioner{},
+			expectedRevokedAt: true,
+		},
+		"revocation-failure-keeps-finalizer": {
+			policy:            issuerapi.RevocationPolicyOnCertificateRequestDelete,
+			finalizers:        []string{revocationFinalizer},
+			deleting:          true,
+			provisioner:       &fakeProvisioner{revokeErr: errors.New("Revoke failure")},
+			expectedError:     true,
+			expectedFinalizer: true,
+		},
+		"rate-limited-revocation-is-requeued": {
+			policy:            issuerapi.RevocationPolicyOnCertificateRequestDelete,
+			finalizers:        []string{revocationFinalizer},
+			deleting:          true,
+			provisioner:       &fakeProvisioner{revokeErr: &awspca.RateLimitedError{Operation: "RevokeCertificate", RetryAfter: 5 * time.Second}},
+			expectedFinalizer: true,
+			expectedResult:    ctrl.Result{RequeueAfter: 5 * time.Second},
+		},
+		"issuer-deleted": {
+			policy:          issuerapi.RevocationPolicyOnCertificateRequestDelete,
+			finalizers:      []string{revocationFinalizer},
+			deleting:        true,
+			noIssuer:        true,
+			provisioner:     &fakeProvisioner{},
+			expectedDeleted: true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			annotations := map[string]string{awspca.CertificateArnAnnotation: "arn"}
+			for key, value := range tc.annotations {
+				annotations[key] = value
+			}
+
+			cr := cmgen.CertificateRequest(
+				"cr1",
+				cmgen.SetCertificateRequestNamespace("ns1"),
+				cmgen.SetCertificateRequestAnnotations(annotations),
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer1",
+					Group: issuerapi.GroupVersion.Group,
+					Kind:  "Issuer",
+				}),
+				cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Reason: cmapi.CertificateRequestReasonIssued,
+					Status: cmmeta.ConditionTrue,
+				}),
+				cmgen.SetCertificateRequestCertificate([]byte("cert")),
+			)
+			cr.Finalizers = tc.finalizers
+			if tc.deleting {
+				now := metav1.Now()
+				cr.DeletionTimestamp = &now
+			}
+
+			objects := []client.Object{cr}
+			if !tc.noIssuer {
+				objects = append(objects, &issuerapi.AWSPCAIssuer{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "issuer1",
+						Namespace: "ns1",
+					},
+					Spec: issuerapi.AWSPCAIssuerSpec{
+						Region:           "us-east-1",
+						Arn:              "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+						RevocationPolicy: tc.policy,
+						RevocationReason: string(acmpcatypes.RevocationReasonSuperseded),
+					},
+					Status: issuerapi.AWSPCAIssuerStatus{
+						Conditions: []metav1.Condition{
+							{
+								Type:   issuerapi.ConditionTypeReady,
+								Status: metav1.ConditionTrue,
+							},
+						},
+					},
+				})
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(objects...).
+				Build()
+			controller := CertificateRequestReconciler{
+				Client:   fakeClient,
+				Log:      logrtesting.NewTestLogger(t),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+				Clock:    clock.RealClock{},
+			}
+			GetProvisioner = generateMockGetProvisioner(tc.provisioner, nil)
+
+			ctx := context.TODO()
+			name := types.NamespacedName{Namespace: "ns1", Name: "cr1"}
+			result, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectedResult, result, "unexpected result")
+
+			assert.Equal(t, tc.expectedRevoked, tc.provisioner.revoked, "unexpected revocations")
+
+			var got cmapi.CertificateRequest
+			err = fakeClient.Get(ctx, name, &got)
+			if tc.expectedDeleted {
+				assert.True(t, apierrors.IsNotFound(err), "expected CertificateRequest to be deleted")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedFinalizer, controllerutil.ContainsFinalizer(&got, revocationFinalizer), "unexpected finalizer")
+			_, revokedAt := got.GetAnnotations()[RevokedAtAnnotation]
+			assert.Equal(t, tc.expectedRevokedAt, revokedAt, "unexpected revoked-at annotation")
+		})
+	}
+}
+
+func TestCertificateRequestIssuance(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		annotations                  map[string]string
+		issuanceTimeout              time.Duration
+		expectedResult               ctrl.Result
+		expectedReadyConditionReason string
+		expectedStartedAt            string
+		expectedAttempts             string
+	}
+
+	tests := map[string]testCase{
+		"signs-and-starts-tracking": {
+			annotations:       map[string]string{},
+			expectedResult:    ctrl.Result{RequeueAfter: issuanceInitialBackoff},
+			expectedStartedAt: "2021-01-01T12:00:00Z",
+			expectedAttempts:  "0",
+		},
+		"backs-off-while-issuing": {
+			annotations: map[string]string{
+				awspca.CertificateArnAnnotation: "arn",
+				IssuanceStartedAtAnnotation:     "2021-01-01T11:59:44Z",
+				IssuanceAttemptsAnnotation:      "3",
+			},
+			issuanceTimeout:              time.Hour,
+			expectedResult:               ctrl.Result{RequeueAfter: 16 * issuanceInitialBackoff},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+			expectedStartedAt:            "2021-01-01T11:59:44Z",
+			expectedAttempts:             "4",
+		},
+		"backoff-is-capped": {
+			annotations: map[string]string{
+				awspca.CertificateArnAnnotation: "arn",
+				IssuanceStartedAtAnnotation:     "2021-01-01T11:30:00Z",
+				IssuanceAttemptsAnnotation:      "40",
+			},
+			expectedResult:               ctrl.Result{RequeueAfter: issuanceMaxBackoff},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+			expectedStartedAt:            "2021-01-01T11:30:00Z",
+			expectedAttempts:             "41",
+		},
+		"starts-tracking-untracked-request": {
+			annotations:                  map[string]string{awspca.CertificateArnAnnotation: "arn"},
+			issuanceTimeout:              time.Hour,
+			expectedResult:               ctrl.Result{RequeueAfter: issuanceInitialBackoff},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+			expectedStartedAt:            "2021-01-01T12:00:00Z",
+			expectedAttempts:             "1",
+		},
+		"fails-after-timeout": {
+			annotations: map[string]string{
+				awspca.CertificateArnAnnotation: "arn",
+				IssuanceStartedAtAnnotation:     "2021-01-01T11:00:00Z",
+				IssuanceAttemptsAnnotation:      "20",
+			},
+			issuanceTimeout:              time.Hour,
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+			expectedStartedAt:            "2021-01-01T11:00:00Z",
+			expectedAttempts:             "20",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := cmgen.CertificateRequest(
+				"cr1",
+				cmgen.SetCertificateRequestNamespace("ns1"),
+				cmgen.SetCertificateRequestAnnotations(tc.annotations),
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer1",
+					Group: issuerapi.GroupVersion.Group,
+					Kind:  "Issuer",
+				}),
+			)
+			issuer := &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+				Spec: issuerapi.AWSPCAIssuerSpec{
+					Arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+				},
+				Status: issuerapi.AWSPCAIssuerStatus{
+					Conditions: []metav1.Condition{{Type: issuerapi.ConditionTypeReady, Status: metav1.ConditionTrue}},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cr, issuer).
+				WithStatusSubresource(cr, issuer).
+				Build()
+			controller := CertificateRequestReconciler{
+				Client:          fakeClient,
+				Log:             logrtesting.NewTestLogger(t),
+				Scheme:          scheme,
+				Recorder:        record.NewFakeRecorder(10),
+				Clock:           clocktesting.NewFakeClock(now),
+				IssuanceTimeout: tc.issuanceTimeout,
+			}
+			GetProvisioner = generateMockGetProvisioner(&fakeProvisioner{getErr: &acmpcatypes.RequestInProgressException{}}, nil)
+
+			ctx := context.TODO()
+			name := types.NamespacedName{Namespace: "ns1", Name: "cr1"}
+			result, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+			require.NoError(t, err)
+			assertRequeue(t, tc.expectedResult, result, "unexpected result")
+
+			var got cmapi.CertificateRequest
+			require.NoError(t, fakeClient.Get(ctx, name, &got))
+			assert.Equal(t, tc.expectedStartedAt, got.GetAnnotations()[IssuanceStartedAtAnnotation])
+			assert.Equal(t, tc.expectedAttempts, got.GetAnnotations()[IssuanceAttemptsAnnotation])
+			if tc.expectedReadyConditionReason != "" {
+				assertCertificateRequestHasReadyCondition(t, cmmeta.ConditionFalse, tc.expectedReadyConditionReason, &got)
+			}
+		})
+	}
+}
+
+func TestCertificateRequestErrors(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	throttled := &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded", Fault: smithy.FaultClient}
+	issuing := map[string]string{
+		awspca.CertificateArnAnnotation: "arn",
+		IssuanceStartedAtAnnotation:     "2021-01-01T11:59:00Z",
+	}
+
+	type testCase struct {
+		annotations                  map[string]string
+		provisioner                  *fakeProvisioner
+		provisionerErr               error
+		expectedResult               ctrl.Result
+		expectedError                bool
+		expectedReadyConditionReason string
+	}
+
+	tests := map[string]testCase{
+		"retryable-sign-error": {
+			provisioner:                  &fakeProvisioner{signErr: throttled},
+			expectedError:                true,
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+		},
+		"permanent-sign-error": {
+			provisioner:                  &fakeProvisioner{signErr: &acmpcatypes.MalformedCSRException{}},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+		},
+		"retryable-get-error": {
+			annotations:                  issuing,
+			provisioner:                  &fakeProvisioner{getErr: throttled},
+			expectedResult:               ctrl.Result{RequeueAfter: issuanceMaxBackoff},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+		},
+		"retryable-get-error-after-timeout": {
+			annotations: map[string]string{
+				awspca.CertificateArnAnnotation: "arn",
+				IssuanceStartedAtAnnotation:     "2021-01-01T11:00:00Z",
+			},
+			provisioner:                  &fakeProvisioner{getErr: throttled},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+		},
+		"permanent-get-error": {
+			annotations:                  issuing,
+			provisioner:                  &fakeProvisioner{getErr: &acmpcatypes.InvalidStateException{}},
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+		},
+		"rate-limited-sign": {
+			provisioner:    &fakeProvisioner{signErr: &awspca.RateLimitedError{Operation: "IssueCertificate", RetryAfter: 5 * time.Second}},
+			expectedResult: ctrl.Result{RequeueAfter: 5 * time.Second},
+		},
+		"rate-limited-get": {
+			annotations:    issuing,
+			provisioner:    &fakeProvisioner{getErr: &awspca.RateLimitedError{Operation: "GetCertificate", RetryAfter: 5 * time.Second}},
+			expectedResult: ctrl.Result{RequeueAfter: 5 * time.Second},
+		},
+		"retryable-provisioner-error": {
+			provisionerErr:               &smithy.GenericAPIError{Code: "AccessDenied", Fault: smithy.FaultClient},
+			expectedError:                true,
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonPending,
+		},
+		"permanent-provisioner-error": {
+			provisionerErr:               &ststypes.RegionDisabledException{},
+			expectedError:                true,
+			expectedReadyConditionReason: cmapi.CertificateRequestReasonFailed,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := cmgen.CertificateRequest(
+				"cr1",
+				cmgen.SetCertificateRequestNamespace("ns1"),
+				cmgen.SetCertificateRequestAnnotations(tc.annotations),
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer1",
+					Group: issuerapi.GroupVersion.Group,
+					Kind:  "Issuer",
+				}),
+			)
+			issuer := &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+				Spec: issuerapi.AWSPCAIssuerSpec{
+					Arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+				},
+				Status: issuerapi.AWSPCAIssuerStatus{
+					Conditions: []metav1.Condition{{Type: issuerapi.ConditionTypeReady, Status: metav1.ConditionTrue}},
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cr, issuer).
+				WithStatusSubresource(cr, issuer).
+				Build()
+			controller := CertificateRequestReconciler{
+				Client:          fakeClient,
+				Log:             logrtesting.NewTestLogger(t),
+				Scheme:          scheme,
+				Recorder:        record.NewFakeRecorder(10),
+				Clock:           clocktesting.NewFakeClock(now),
+				IssuanceTimeout: time.Hour,
+			}
+			if tc.provisionerErr != nil {
+				GetProvisioner = generateMockGetProvisioner(nil, tc.provisionerErr)
+			} else {
+				GetProvisioner = generateMockGetProvisioner(tc.provisioner, nil)
+			}
+
+			ctx := context.TODO()
+			name := types.NamespacedName{Namespace: "ns1", Name: "cr1"}
+			result, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assertRequeue(t, tc.expectedResult, result, "unexpected result")
+
+			var got cmapi.CertificateRequest
+			require.NoError(t, fakeClient.Get(ctx, name, &got))
+			if tc.expectedReadyConditionReason != "" {
+				assertCertificateRequestHasReadyCondition(t, cmmeta.ConditionFalse, tc.expectedReadyConditionReason, &got)
+			} else {
+				assert.Empty(t, got.Status.Conditions, "expected the status to be left alone")
+			}
+		})
+	}
+}
+
+// shorteningProvisioner issues certificates that are valid for less time than
+// requested
+type shorteningProvisioner struct {
+	*fakeProvisioner
+	shortened string
+}
+
+func (p *shorteningProvisioner) Sign(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) error {
+	metav1.SetMetaDataAnnotation(&cr.ObjectMeta, awspca.ValidityShortenedAnnotation, p.shortened)
+	return p.fakeProvisioner.Sign(ctx, cr, log)
+}
+
+func TestCertificateRequestValidityShortened(t *testing.T) {
+	const shortened = "the requested duration 48h0m0s was shortened to the issuer's maxDuration of 24h0m0s"
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+
+	cr := cmgen.CertificateRequest(
+		"cr1",
+		cmgen.SetCertificateRequestNamespace("ns1"),
+		cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+			Name:  "issuer1",
+			Group: issuerapi.GroupVersion.Group,
+			Kind:  "Issuer",
+		}),
+	)
+	issuer := &issuerapi.AWSPCAIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+		Spec: issuerapi.AWSPCAIssuerSpec{
+			Arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+		},
+		Status: issuerapi.AWSPCAIssuerStatus{
+			Conditions: []metav1.Condition{{Type: issuerapi.ConditionTypeReady, Status: metav1.ConditionTrue}},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cr, issuer).
+		WithStatusSubresource(cr, issuer).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	controller := CertificateRequestReconciler{
+		Client:   fakeClient,
+		Log:      logrtesting.NewTestLogger(t),
+		Scheme:   scheme,
+		Recorder: recorder,
+		Clock:    clock.RealClock{},
+	}
+	provisioner := &shorteningProvisioner{fakeProvisioner: &fakeProvisioner{cert: []byte("cert")}, shortened: shortened}
+	GetProvisioner = func(context.Context, client.Client, types.NamespacedName, *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
+		return provisioner, nil
+	}
+
+	ctx := context.TODO()
+	name := types.NamespacedName{Namespace: "ns1", Name: "cr1"}
+	_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+	require.NoError(t, err)
+	require.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning ValidityShortened "+shortened, <-recorder.Events)
+
+	_, err = controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+	require.NoError(t, err)
+
+	var got cmapi.CertificateRequest
+	require.NoError(t, fakeClient.Get(ctx, name, &got))
+	condition := cmutil.GetCertificateRequestCondition(&got, cmapi.CertificateRequestConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, cmapi.CertificateRequestReasonIssued, condition.Reason)
+	assert.Equal(t, "certificate issued, "+shortened, condition.Message)
+}
+
+func TestCertificateRequestPredicate(t *testing.T) {
+	ours := cmmeta.ObjectReference{Name: "issuer1", Group: issuerapi.GroupVersion.Group, Kind: "Issuer"}
+	ready := func(status cmmeta.ConditionStatus, reason string) cmgen.CertificateRequestModifier {
+		return cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+			Type:   cmapi.CertificateRequestConditionReady,
+			Status: status,
+			Reason: reason,
+		})
+	}
+
+	type testCase struct {
+		modifiers []cmgen.CertificateRequestModifier
+		expected  bool
+	}
+
+	tests := map[string]testCase{
+		"new": {
+			modifiers: []cmgen.CertificateRequestModifier{cmgen.SetCertificateRequestIssuer(ours)},
+			expected:  true,
+		},
+		"pending": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionFalse, cmapi.CertificateRequestReasonPending),
+			},
+			expected: true,
+		},
+		"other-issuer": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer1", Group: "cert-manager.io", Kind: "Issuer"}),
+			},
+			expected: false,
+		},
+		"issued": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued),
+			},
+			expected: false,
+		},
+		"failed": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionFalse, cmapi.CertificateRequestReasonFailed),
+			},
+			expected: false,
+		},
+		"denied": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionFalse, cmapi.CertificateRequestReasonDenied),
+			},
+			expected: false,
+		},
+		"issued-and-deleting": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued),
+				func(cr *cmapi.CertificateRequest) {
+					now := metav1.Now()
+					cr.DeletionTimestamp = &now
+				},
+			},
+			expected: true,
+		},
+		"issued-and-revoke-requested": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued),
+				cmgen.SetCertificateRequestAnnotations(map[string]string{RevokeAnnotation: "true"}),
+			},
+			expected: true,
+		},
+		"issued-and-revoked": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestIssuer(ours),
+				ready(cmmeta.ConditionTrue, cmapi.CertificateRequestReasonIssued),
+				cmgen.SetCertificateRequestAnnotations(map[string]string{
+					RevokeAnnotation:    "true",
+					RevokedAtAnnotation: "2021-01-01T12:00:00Z",
+				}),
+			},
+			expected: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := cmgen.CertificateRequest("cr1", tc.modifiers...)
+			p := certificateRequestPredicate()
+			assert.Equal(t, tc.expected, p.Create(event.CreateEvent{Object: cr}), "unexpected create")
+			assert.Equal(t, tc.expected, p.Update(event.UpdateEvent{ObjectOld: cmgen.CertificateRequest("cr1"), ObjectNew: cr}), "unexpected update")
+		})
+	}
+}
+
+func TestCertificateRequestPredicateUpdates(t *testing.T) {
+	base := cmgen.CertificateRequest(
+		"cr1",
+		cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{Name: "issuer1", Group: issuerapi.GroupVersion.Group, Kind: "Issuer"}),
+	)
+
+	type testCase struct {
+		modifiers []cmgen.CertificateRequestModifier
+		expected  bool
+	}
+
+	tests := map[string]testCase{
+		"issuance-tracking": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestAnnotations(map[string]string{
+					awspca.CertificateArnAnnotation: "arn",
+					IssuanceStartedAtAnnotation:     "2021-01-01T12:00:00Z",
+					IssuanceAttemptsAnnotation:      "2",
+				}),
+				cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionReady,
+					Status: cmmeta.ConditionFalse,
+					Reason: cmapi.CertificateRequestReasonPending,
+				}),
+			},
+			expected: false,
+		},
+		"approved": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+					Type:   cmapi.CertificateRequestConditionApproved,
+					Status: cmmeta.ConditionTrue,
+				}),
+			},
+			expected: true,
+		},
+		"revoke-requested": {
+			modifiers: []cmgen.CertificateRequestModifier{
+				cmgen.SetCertificateRequestAnnotations(map[string]string{RevokeAnnotation: "true"}),
+			},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			updated := cmgen.CertificateRequestFrom(base, tc.modifiers...)
+			p := certificateRequestPredicate()
+			assert.Equal(t, tc.expected, p.Update(event.UpdateEvent{ObjectOld: base, ObjectNew: updated}))
+		})
+	}
+}
+
+// TestIssuancePollIsPacedByBackoff reconciles CertificateRequests that PCA is
+// still issuing, and passes each write of the controller through its predicate
+// as the watch would. None of them may trigger a reconciliation, which would
+// bypass the issuance backoff.
+func TestIssuancePollIsPacedByBackoff(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		annotations map[string]string
+	}
+
+	tests := map[string]testCase{
+		"signed": {
+			annotations: map[string]string{},
+		},
+		"issuing": {
+			annotations: map[string]string{
+				awspca.CertificateArnAnnotation: "arn",
+				IssuanceStartedAtAnnotation:     "2021-01-01T11:59:50Z",
+			},
+		},
+		"untracked": {
+			annotations: map[string]string{awspca.CertificateArnAnnotation: "arn"},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, cmapi.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cr := cmgen.CertificateRequest(
+				"cr1",
+				cmgen.SetCertificateRequestNamespace("ns1"),
+				cmgen.SetCertificateRequestAnnotations(tc.annotations),
+				cmgen.SetCertificateRequestIssuer(cmmeta.ObjectReference{
+					Name:  "issuer1",
+					Group: issuerapi.GroupVersion.Group,
+					Kind:  "Issuer",
+				}),
+			)
+			issuer := &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+				Spec: issuerapi.AWSPCAIssuerSpec{
+					Arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+				},
+				Status: issuerapi.AWSPCAIssuerStatus{
+					Conditions: []metav1.Condition{{Type: issuerapi.ConditionTypeReady, Status: metav1.ConditionTrue}},
+				},
+			}
+
+			// update makes a write and reports the resulting event to the predicate
+			p := certificateRequestPredicate()
+			var triggered []string
+			update := func(ctx context.Context, c client.Client, obj client.Object, write func() error) error {
+				oldObj := &cmapi.CertificateRequest{}
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), oldObj); err != nil {
+					return err
+				}
+				if err := write(); err != nil {
+					return err
+				}
+				newObj := &cmapi.CertificateRequest{}
+				if err := c.Get(ctx, client.ObjectKeyFromObject(obj), newObj); err != nil {
+					return err
+				}
+				if p.Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj}) {
+					triggered = append(triggered, fmt.Sprintf("annotations=%v conditions=%v", newObj.Annotations, newObj.Status.Conditions))
+				}
+				return nil
+			}
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(cr, issuer).
+				WithStatusSubresource(cr, issuer).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+						return update(ctx, c, obj, func() error { return c.Update(ctx, obj, opts...) })
+					},
+					SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+						return update(ctx, c, obj, func() error { return c.SubResource(subResourceName).Update(ctx, obj, opts...) })
+					},
+				}).
+				Build()
+			clock := clocktesting.NewFakeClock(now)
+			controller := CertificateRequestReconciler{
+				Client:          fakeClient,
+				Log:             logrtesting.NewTestLogger(t),
+				Scheme:          scheme,
+				Recorder:        record.NewFakeRecorder(10),
+				Clock:           clock,
+				IssuanceTimeout: time.Hour,
+			}
+			GetProvisioner = generateMockGetProvisioner(&fakeProvisioner{getErr: &acmpcatypes.RequestInProgressException{}}, nil)
+
+			ctx := context.TODO()
+			name := types.NamespacedName{Namespace: "ns1", Name: "cr1"}
+			for i := 0; i < 3; i++ {
+				result, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+				require.NoError(t, err)
+				require.GreaterOrEqual(t, result.RequeueAfter, issuanceInitialBackoff, "reconcile %d", i)
+				clock.Step(result.RequeueAfter)
+			}
+			assert.Empty(t, triggered, "expected no write to trigger a reconciliation")
+		})
+	}
+}
+
+// slowProvisioner takes latency for each call to PCA
+type slowProvisioner struct {
+	*fakeProvisioner
+	latency time.Duration
+}
+
+func (p *slowProvisioner) Sign(ctx context.Context, cr *cmapi.CertificateRequest, log logr.Logger) error {
+	time.Sleep(p.latency)
+	return p.fakeProvisioner.Sign(ctx, cr, log)
+}
+
+func (p *slowProvisioner) Get(ctx context.Context, cr *cmapi.CertificateRequest, certArn string, log logr.Logger) ([]byte, []byte, error) {
+	time.Sleep(p.latency)
+	return p.fakeProvisioner.Get(ctx, cr, certArn, log)
+}
+
+// BenchmarkCertificateRequestReconcileThroughput issues thousands of
+// CertificateRequests by calling Reconcile from different numbers of
+// goroutines. It measures how Reconcile scales when it runs concurrently, not
+// controller-runtime's workqueue or MaxConcurrentReconciles. A quarter of the
+// requests are for other issuers and another quarter have already been
+// issued, and are filtered out by the predicate. PCA takes a millisecond per
+// call.
+func BenchmarkCertificateRequestReconcileThroughput(b *testing.B) {
+	const requests = 4000
+
+	scheme := runtime.NewScheme()
+	require.NoError(b, issuerapi.AddToScheme(scheme))
+	require.NoError(b, cmapi.AddToScheme(scheme))
+
+	issuer := &issuerapi.AWSPCAIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+		Spec: issuerapi.AWSPCAIssuerSpec{
+			Arn: "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+		},
+		Status: issuerapi.AWSPCAIssuerStatus{
+			Conditions: []metav1.Condition{{Type: issuerapi.ConditionTypeReady, Status: metav1.ConditionTrue}},
+		},
+	}
+	objects := []client.Object{issuer}
+	for i := 0; i < requests; i++ {
+		ref := cmmeta.ObjectReference{Name: "issuer1", Group: issuerapi.GroupVersion.Group, Kind: "Issuer"}
+		modifiers := []cmgen.CertificateRequestModifier{cmgen.SetCertificateRequestNamespace("ns1")}
+		switch i % 4 {
+		case 0:
+			ref.Group = "cert-manager.io"
+		case 1:
+			modifiers = append(modifiers, cmgen.SetCertificateRequestStatusCondition(cmapi.CertificateRequestCondition{
+				Type:   cmapi.CertificateRequestConditionReady,
+				Status: cmmeta.ConditionTrue,
+				Reason: cmapi.CertificateRequestReasonIssued,
+			}))
+		}
+		modifiers = append(modifiers, cmgen.SetCertificateRequestIssuer(ref))
+		objects = append(objects, cmgen.CertificateRequest(fmt.Sprintf("cr%d", i), modifiers...))
+	}
+
+	provisioner := &slowProvisioner{fakeProvisioner: &fakeProvisioner{cert: []byte("cert")}, latency: time.Millisecond}
+	getProvisioner := GetProvisioner
+	b.Cleanup(func() { GetProvisioner = getProvisioner })
+	GetProvisioner = func(context.Context, client.Client, types.NamespacedName, *issuerapi.AWSPCAIssuerSpec) (awspca.GenericProvisioner, error) {
+		return provisioner, nil
+	}
+
+	for _, callers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("callers-%d", callers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				fakeClient := fake.NewClientBuilder().
+					WithScheme(scheme).
+					WithObjects(objects...).
+					WithStatusSubresource(objects...).
+					Build()
+				controller := CertificateRequestReconciler{
+					Client:   fakeClient,
+					Log:      logr.Discard(),
+					Scheme:   scheme,
+					Recorder: &record.FakeRecorder{},
+					Clock:    clock.RealClock{},
+				}
+				b.StartTimer()
+
+				issued := reconcileAll(b, &controller, objects, callers)
+				if issued != requests/2 {
+					b.Fatalf("expected %d requests to be issued, got %d", requests/2, issued)
+				}
+			}
+			b.ReportMetric(float64(requests*b.N)/b.Elapsed().Seconds(), "requests/s")
+		})
+	}
+}
+
+// reconcileAll reconciles the CertificateRequests passed by the predicate from
+// the given number of goroutines, requeueing them without delay until they
+// need no more work, and returns how many were issued
+func reconcileAll(b *testing.B, controller *CertificateRequestReconciler, objects []client.Object, callers int) int {
+	p := certificateRequestPredicate()
+	queue := make(chan types.NamespacedName, len(objects))
+	var pending sync.WaitGroup
+	for _, obj := range objects {
+		if cr, ok := obj.(*cmapi.CertificateRequest); ok && p.Create(event.CreateEvent{Object: cr}) {
+			pending.Add(1)
+			queue <- client.ObjectKeyFromObject(cr)
+		}
+	}
+
+	var issued atomic.Int64
+	ctx := context.TODO()
+	for i := 0; i < callers; i++ {
+		go func() {
+			for name := range queue {
+				result, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name})
+				switch {
+				case err != nil || result.RequeueAfter > 0:
+					pending.Add(1)
+					queue <- name
+				default:
+					issued.Add(1)
+				}
+				pending.Done()
+			}
+		}()
+	}
+	pending.Wait()
+	close(queue)
+
+	return int(issued.Load())
+}
+
+// assertRequeue checks that a result requeues like the expected result, where
+// RequeueAfter may be up to issuanceBackoffJitter longer than expected
+func assertRequeue(t *testing.T, expected, actual ctrl.Result, msg string) {
+	assert.Equal(t, expected.Requeue, actual.Requeue, msg)
+	maxRequeueAfter := time.Duration(float64(expected.RequeueAfter) * (1 + issuanceBackoffJitter))
+	assert.True(t, actual.RequeueAfter >= expected.RequeueAfter && actual.RequeueAfter <= maxRequeueAfter,
+		"%s: expected RequeueAfter between %s and %s, got %s", msg, expected.RequeueAfter, maxRequeueAfter, actual.RequeueAfter)
+}
+
 func assertCertificateRequestHasReadyCondition(t *testing.T, status cmmeta.ConditionStatus, reason string, cr *cmapi.CertificateRequest) {
 	condition := cmutil.GetCertificateRequestCondition(cr, cmapi.CertificateRequestConditionReady)
 	if !assert.NotNil(t, condition, "Ready condition not found") {
@@ -20,27 +20,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
 	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
 	awspca "github.com/cert-manager/aws-privateca-issuer/pkg/aws"
 	"github.com/cert-manager/aws-privateca-issuer/pkg/util"
 	"github.com/go-logr/logr"
 	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 var (
-	errNoArnInSpec    = errors.New("no Arn found in Issuer Spec")
-	errNoRegionInSpec = errors.New("no Region found in Issuer Spec")
+	errNoArnInSpec = errors.New("no Arn found in Issuer Spec")
 )
 
-var awsDefaultRegion = os.Getenv("AWS_REGION")
+// Reasons of the issuer Ready condition when the CA cannot issue certificates
+const (
+	reasonCADisabled           = "CADisabled"
+	reasonCAExpired            = "CAExpired"
+	reasonCAPendingCertificate = "CAPendingCertificate"
+	reasonCANotActive          = "CANotActive"
+	reasonAccessDenied         = "AccessDenied"
+	reasonNotFound             = "NotFound"
+	// The CA's key cannot be used with the issuer's signingAlgorithm
+	reasonSigningAlgorithmMismatch = "SigningAlgorithmMismatch"
+)
 
 // GenericIssuerReconciler reconciles both AWSPCAIssuer and AWSPCAClusterIssuer objects
 type GenericIssuerReconciler struct {
@@ -55,6 +73,15 @@ type GenericIssuerReconciler struct {
 	// but can be skipped during unit tests to avoid having a dependency on a
 	// live STS service.
 	GetCallerIdentity bool
+
+	Clock clock.PassiveClock
+
+	// ResyncInterval is how often issuers are re-verified. Zero disables
+	// periodic re-verification. Issuers may override it with spec.resyncInterval.
+	ResyncInterval time.Duration
+	// CAExpiryWarningThreshold is how long before the CA certificate expires
+	// the CAExpiringSoon condition is raised. Zero disables the check.
+	CAExpiryWarningThreshold time.Duration
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -65,15 +92,26 @@ type GenericIssuerReconciler struct {
 func (r *GenericIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Request, issuer api.GenericIssuer) (ctrl.Result, error) {
 	log := r.Log.WithValues("genericissuer", req.NamespacedName)
 	spec := issuer.GetSpec()
-	err := validateIssuer(spec)
+
+	// Always drop the cached provisioner, so that changes to the issuer or
+	// to the Secret holding its credentials take effect.
+	awspca.DeleteProvisioner(ctx, r.Client, req.NamespacedName)
+
+	err := validateIssuer(req.NamespacedName, spec)
 	if err != nil {
 		log.Error(err, "failed to validate issuer")
 		_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, "Validation", "Failed to validate resource: %v", err)
 		return ctrl.Result{}, err
 	}
 
-	awspca.DeleteProvisioner(ctx, r.Client, req.NamespacedName)
-	cfg, err := awspca.GetConfig(ctx, r.Client, spec)
+	err = awspca.ValidateSecretNamespaces(ctx, r.Client, req.NamespacedName, spec)
+	if err != nil {
+		log.Error(err, "failed to validate issuer secrets")
+		_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, "Validation", "Failed to validate resource: %v", err)
+		return ctrl.Result{}, err
+	}
+
+	cfg, err := awspca.GetConfig(ctx, r.Client, req.NamespacedName, spec)
 	if err != nil {
 		log.Error(err, "Error loading config")
 		_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, "Error", err.Error())
@@ -81,21 +119,247 @@ func (r *GenericIssuerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	if r.GetCallerIdentity {
-		id, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+		id, err := awspca.NewSTSClient(cfg, spec).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 		if err != nil {
 			log.Error(err, "failed to sts.GetCallerIdentity")
 			return ctrl.Result{}, err
 		}
 		log.Info("sts.GetCallerIdentity", "arn", id.Arn, "account", id.Account, "user_id", id.UserId)
+		issuer.GetStatus().AccountID = aws.ToString(id.Account)
+	}
+
+	provisioner, err := NewProvisioner(cfg, req.NamespacedName, spec)
+	if err != nil {
+		log.Error(err, "failed to create provisioner")
+		_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, "Error", err.Error())
+		return ctrl.Result{}, err
+	}
+
+	ca, err := provisioner.DescribeCertificateAuthority(ctx)
+	if err == nil {
+		setCertificateAuthorityStatus(issuer.GetStatus(), ca)
+		r.checkCertificateAuthorityExpiry(issuer)
+	}
+	if reason, message := certificateAuthorityHealth(ca, err); reason != "" {
+		if err == nil {
+			err = errors.New(message)
+		}
+		log.Error(err, "certificate authority cannot issue certificates", "reason", reason)
+		_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, reason, "%s", message)
+		return ctrl.Result{}, err
+	}
+
+	if spec.SigningAlgorithm != "" {
+		if err := awspca.ValidateSigningAlgorithmForCA(acmpcatypes.SigningAlgorithm(spec.SigningAlgorithm), ca); err != nil {
+			log.Error(err, "certificate authority cannot sign with the issuer's signing algorithm")
+			_ = r.setStatus(ctx, issuer, metav1.ConditionFalse, reasonSigningAlgorithmMismatch, "%s", err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{RequeueAfter: r.resyncInterval(spec)}, r.setStatus(ctx, issuer, metav1.ConditionTrue, "Verified", "Issuer verified")
+}
+
+// resyncInterval returns how long to wait before re-verifying the issuer
+func (r *GenericIssuerReconciler) resyncInterval(spec *api.AWSPCAIssuerSpec) time.Duration {
+	if spec.ResyncInterval != nil {
+		return spec.ResyncInterval.Duration
+	}
+	return r.ResyncInterval
+}
+
+// checkCertificateAuthorityExpiry sets the CAExpiringSoon condition from the
+// NotAfter recorded in the issuer status, and emits a Warning event when the
+// CA starts expiring soon.
+func (r *GenericIssuerReconciler) checkCertificateAuthorityExpiry(issuer api.GenericIssuer) {
+	notAfter := issuer.GetStatus().NotAfter
+	if r.CAExpiryWarningThreshold <= 0 || notAfter == nil {
+		return
+	}
+
+	log := r.Log.WithValues("genericissuer", issuer.GetName())
+	remaining := notAfter.Sub(r.Clock.Now())
+	if remaining > r.CAExpiryWarningThreshold {
+		util.SetIssuerCondition(log, issuer, api.ConditionTypeCAExpiringSoon, metav1.ConditionFalse, "CAValid",
+			fmt.Sprintf("Certificate authority expires at %s", notAfter.UTC().Format(time.RFC3339)))
+		return
+	}
+
+	message := fmt.Sprintf("Certificate authority expires at %s, in %s", notAfter.UTC().Format(time.RFC3339), remaining.Round(time.Minute))
+	if remaining <= 0 {
+		message = fmt.Sprintf("Certificate authority expired at %s", notAfter.UTC().Format(time.RFC3339))
+	}
+	if !isConditionTrue(issuer, api.ConditionTypeCAExpiringSoon) {
+		r.Recorder.Event(issuer, core.EventTypeWarning, api.ConditionTypeCAExpiringSoon, message)
+	}
+	util.SetIssuerCondition(log, issuer, api.ConditionTypeCAExpiringSoon, metav1.ConditionTrue, api.ConditionTypeCAExpiringSoon, message)
+}
+
+func isConditionTrue(issuer api.GenericIssuer, conditionType string) bool {
+	for _, cond := range issuer.GetStatus().Conditions {
+		if cond.Type == conditionType {
+			return cond.Status == metav1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// certificateAuthorityHealth maps the result of DescribeCertificateAuthority to
+// the reason and message of the issuer's Ready condition. An empty reason
+// means that the CA is able to issue certificates.
+func certificateAuthorityHealth(ca *acmpcatypes.CertificateAuthority, err error) (string, string) {
+	if err != nil {
+		var notFound *acmpcatypes.ResourceNotFoundException
+		var apiErr smithy.APIError
+		switch {
+		case errors.As(err, &notFound):
+			return reasonNotFound, "Certificate authority not found: " + err.Error()
+		case errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDeniedException":
+			return reasonAccessDenied, "Access to the certificate authority was denied: " + err.Error()
+		default:
+			return "Error", "Failed to describe the certificate authority: " + err.Error()
+		}
+	}
+
+	switch ca.Status {
+	case acmpcatypes.CertificateAuthorityStatusActive:
+		return "", ""
+	case acmpcatypes.CertificateAuthorityStatusDisabled:
+		return reasonCADisabled, "Certificate authority is disabled"
+	case acmpcatypes.CertificateAuthorityStatusExpired:
+		return reasonCAExpired, "Certificate authority has expired"
+	case acmpcatypes.CertificateAuthorityStatusPendingCertificate:
+		return reasonCAPendingCertificate, "Certificate authority is waiting for its certificate to be installed"
+	case acmpcatypes.CertificateAuthorityStatusDeleted:
+		return reasonNotFound, "Certificate authority has been deleted"
+	default:
+		return reasonCANotActive, fmt.Sprintf("Certificate authority is in state %s", ca.Status)
+	}
+}
+
+// setCertificateAuthorityStatus records the certificate authority's metadata in the issuer status
+func setCertificateAuthorityStatus(status *api.AWSPCAIssuerStatus, ca *acmpcatypes.CertificateAuthority) {
+	status.CAStatus = string(ca.Status)
+	status.CAType = string(ca.Type)
+	status.UsageMode = string(ca.UsageMode)
+	status.Serial = aws.ToString(ca.Serial)
+	status.KeyAlgorithm = ""
+	status.SigningAlgorithm = ""
+	if config := ca.CertificateAuthorityConfiguration; config != nil {
+		status.KeyAlgorithm = string(config.KeyAlgorithm)
+		status.SigningAlgorithm = string(config.SigningAlgorithm)
+	}
+	status.NotBefore = optionalTime(ca.NotBefore)
+	status.NotAfter = optionalTime(ca.NotAfter)
+}
+
+func optionalTime(t *time.Time) *metav1.Time {
+	if t == nil {
+		return nil
+	}
+	mt := metav1.NewTime(*t)
+	return &mt
+}
+
+// secretRefIndexField indexes issuers by the namespace/name of the Secrets
+// referenced by spec.secretRef and spec.rolesAnywhere
+const secretRefIndexField = ".spec.secretRef"
+
+func indexIssuerSecretRef(obj client.Object) []string {
+	issuer, ok := obj.(api.GenericIssuer)
+	if !ok {
+		return nil
+	}
+
+	var secrets []string
+	for _, secret := range awspca.IssuerSecrets(issuer.GetSpec()) {
+		secrets = append(secrets, secret.String())
+	}
+	return secrets
+}
+
+// credentialsRefIndexField indexes issuers by the Kind/namespace/name of the
+// credentials referenced by spec.credentialsRef
+const credentialsRefIndexField = ".spec.credentialsRef"
+
+func indexIssuerCredentialsRef(obj client.Object) []string {
+	issuer, ok := obj.(api.GenericIssuer)
+	if !ok {
+		return nil
 	}
+	name := types.NamespacedName{Namespace: issuer.GetNamespace(), Name: issuer.GetName()}
+	if key := awspca.CredentialsRefKey(name, issuer.GetSpec().CredentialsRef); key != "" {
+		return []string{key}
+	}
+	return nil
+}
+
+// issuersForSecret returns a request for every issuer in list that references
+// secret, either directly or through shared credentials
+func issuersForSecret(ctx context.Context, c client.Client, list client.ObjectList, secret client.Object) []reconcile.Request {
+	secretName := types.NamespacedName{Namespace: secret.GetNamespace(), Name: secret.GetName()}
+	requests := listIssuers(ctx, c, list, secretRefIndexField, secretName.String())
+
+	credentials := new(api.AWSPCACredentialsList)
+	if err := c.List(ctx, credentials); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list credentials", "secret", secretName)
+	}
+	for i := range credentials.Items {
+		if referencesSecret(&credentials.Items[i].Spec, secretName) {
+			requests = append(requests, issuersForCredentials(ctx, c, list, api.CredentialsKind, &credentials.Items[i])...)
+		}
+	}
+
+	clusterCredentials := new(api.AWSPCAClusterCredentialsList)
+	if err := c.List(ctx, clusterCredentials); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list cluster credentials", "secret", secretName)
+	}
+	for i := range clusterCredentials.Items {
+		if referencesSecret(&clusterCredentials.Items[i].Spec, secretName) {
+			requests = append(requests, issuersForCredentials(ctx, c, list, api.ClusterCredentialsKind, &clusterCredentials.Items[i])...)
+		}
+	}
+	return requests
+}
+
+func referencesSecret(spec *api.AWSPCACredentialsSpec, secret types.NamespacedName) bool {
+	for _, name := range awspca.CredentialsSecrets(spec) {
+		if name == secret {
+			return true
+		}
+	}
+	return false
+}
 
-	return ctrl.Result{}, r.setStatus(ctx, issuer, metav1.ConditionTrue, "Verified", "Issuer verified")
+// issuersForCredentials returns a request for every issuer in list that references credentials
+func issuersForCredentials(ctx context.Context, c client.Client, list client.ObjectList, kind string, credentials client.Object) []reconcile.Request {
+	key := strings.Join([]string{kind, credentials.GetNamespace(), credentials.GetName()}, "/")
+	return listIssuers(ctx, c, list, credentialsRefIndexField, key)
+}
+
+// listIssuers returns a request for every issuer in list whose index field matches key
+func listIssuers(ctx context.Context, c client.Client, list client.ObjectList, field, key string) []reconcile.Request {
+	if err := c.List(ctx, list, client.MatchingFields{field: key}); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list issuers", field, key)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	_ = meta.EachListItem(list, func(obj runtime.Object) error {
+		issuer := obj.(client.Object)
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: issuer.GetNamespace(), Name: issuer.GetName()},
+		})
+		return nil
+	})
+	return requests
 }
 
 func (r *GenericIssuerReconciler) setStatus(ctx context.Context, issuer api.GenericIssuer, status metav1.ConditionStatus, reason, message string, args ...interface{}) error {
 	log := r.Log.WithValues("genericissuer", issuer.GetName())
 	completeMessage := fmt.Sprintf(message, args...)
 	util.SetIssuerCondition(log, issuer, api.ConditionTypeReady, status, reason, completeMessage)
+	issuer.GetStatus().ObservedGeneration = issuer.GetGeneration()
 
 	eventType := core.EventTypeNormal
 	if status == metav1.ConditionFalse {
@@ -106,12 +370,11 @@ func (r *GenericIssuerReconciler) setStatus(ctx context.Context, issuer api.Gene
 	return r.Client.Status().Update(ctx, issuer)
 }
 
-func validateIssuer(spec *api.AWSPCAIssuerSpec) error {
-	switch {
-	case spec.Arn == "":
+// validateIssuer checks the issuer spec with the rules of the validating
+// webhook, so that issuers admitted without it are caught too
+func validateIssuer(name types.NamespacedName, spec *api.AWSPCAIssuerSpec) error {
+	if spec.Arn == "" {
 		return errNoArnInSpec
-	case spec.Region == "" && awsDefaultRegion == "":
-		return errNoRegionInSpec
 	}
-	return nil
+	return awspca.ValidateIssuerSpec(name, spec, field.NewPath("spec")).ToAggregate()
 }
@@ -16,9 +16,15 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	acmpcatypes "github.com/aws/aws-sdk-go-v2/service/acmpca/types"
+	"github.com/aws/smithy-go"
 	awspca "github.com/cert-manager/aws-privateca-issuer/pkg/aws"
 	logrtesting "github.com/go-logr/logr/testing"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +34,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	clocktesting "k8s.io/utils/clock/testing"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -178,7 +185,7 @@ func TestIssuerReconcile(t *testing.T) {
 			expectedReadyConditionStatus: metav1.ConditionTrue,
 			expectedResult:               ctrl.Result{},
 		},
-		"failure-issuer-no-region-specified": {
+		"success-issuer-region-from-arn": {
 			name: types.NamespacedName{Namespace: "ns1", Name: "issuer1"},
 			objects: []client.Object{
 				&issuerapi.AWSPCAIssuer{
@@ -215,8 +222,7 @@ func TestIssuerReconcile(t *testing.T) {
 					},
 				},
 			},
-			expectedReadyConditionStatus: metav1.ConditionFalse,
-			expectedError:                errNoRegionInSpec,
+			expectedReadyConditionStatus: metav1.ConditionTrue,
 			expectedResult:               ctrl.Result{},
 		},
 		"failure-issuer-no-arn-specified": {
@@ -452,6 +458,7 @@ func TestIssuerReconcile(t *testing.T) {
 				Scheme:   scheme,
 				Recorder: record.NewFakeRecorder(10),
 			}
+			NewProvisioner = generateMockNewProvisioner(&fakeProvisioner{}, nil)
 
 			var (
 				result reconcile.Result
@@ -494,6 +501,609 @@ func TestIssuerReconcile(t *testing.T) {
 	}
 }
 
+func TestIssuerReconcileCertificateAuthorityHealth(t *testing.T) {
+	rsaCertificateAuthority := &acmpcatypes.CertificateAuthority{
+		Status: acmpcatypes.CertificateAuthorityStatusActive,
+		CertificateAuthorityConfiguration: &acmpcatypes.CertificateAuthorityConfiguration{
+			KeyAlgorithm:     acmpcatypes.KeyAlgorithmRsa2048,
+			SigningAlgorithm: acmpcatypes.SigningAlgorithmSha256withrsa,
+		},
+	}
+
+	type testCase struct {
+		provisioner                  *fakeProvisioner
+		signingAlgorithm             string
+		expectedReadyConditionStatus metav1.ConditionStatus
+		expectedReadyConditionReason string
+	}
+
+	tests := map[string]testCase{
+		"active": {
+			provisioner:                  &fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusActive}},
+			expectedReadyConditionStatus: metav1.ConditionTrue,
+			expectedReadyConditionReason: "Verified",
+		},
+		"disabled": {
+			provisioner:                  &fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusDisabled}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonCADisabled,
+		},
+		"expired": {
+			provisioner:                  &fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusExpired}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonCAExpired,
+		},
+		"pending-certificate": {
+			provisioner:                  &fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusPendingCertificate}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonCAPendingCertificate,
+		},
+		"creating": {
+			provisioner:                  &fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{Status: acmpcatypes.CertificateAuthorityStatusCreating}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonCANotActive,
+		},
+		"access-denied": {
+			provisioner: &fakeProvisioner{describeErr: &smithy.GenericAPIError{
+				Code:    "AccessDeniedException",
+				Message: "User is not authorized to perform: acm-pca:DescribeCertificateAuthority",
+			}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonAccessDenied,
+		},
+		"not-found": {
+			provisioner:                  &fakeProvisioner{describeErr: &acmpcatypes.ResourceNotFoundException{}},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonNotFound,
+		},
+		"other-error": {
+			provisioner:                  &fakeProvisioner{describeErr: errors.New("connection reset")},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Error",
+		},
+		"signing-algorithm-matches-key": {
+			provisioner:                  &fakeProvisioner{ca: rsaCertificateAuthority},
+			signingAlgorithm:             "SHA512WITHRSA",
+			expectedReadyConditionStatus: metav1.ConditionTrue,
+			expectedReadyConditionReason: "Verified",
+		},
+		"signing-algorithm-mismatch": {
+			provisioner:                  &fakeProvisioner{ca: rsaCertificateAuthority},
+			signingAlgorithm:             "SHA384WITHECDSA",
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: reasonSigningAlgorithmMismatch,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iss := &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "issuer1",
+					Namespace: "ns1",
+				},
+				Spec: issuerapi.AWSPCAIssuerSpec{
+					Region:           "us-east-1",
+					Arn:              "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+					SigningAlgorithm: tc.signingAlgorithm,
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(iss).
+				WithStatusSubresource(iss).
+				Build()
+
+			controller := GenericIssuerReconciler{
+				Client:   fakeClient,
+				Log:      logrtesting.NewTestLogger(t),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			NewProvisioner = generateMockNewProvisioner(tc.provisioner, nil)
+
+			ctx := context.TODO()
+			name := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+			_, err := controller.Reconcile(ctx, reconcile.Request{NamespacedName: name}, iss)
+			if tc.expectedReadyConditionStatus == metav1.ConditionTrue {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			assertIssuerHasReadyCondition(t, tc.expectedReadyConditionStatus, &iss.Status)
+			assert.Equal(t, tc.expectedReadyConditionReason, iss.Status.Conditions[0].Reason, "unexpected condition reason")
+		})
+	}
+}
+
+func TestIssuerReconcileCertificateAuthorityStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	iss := &issuerapi.AWSPCAClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "clusterissuer1",
+			Generation: 3,
+		},
+		Spec: issuerapi.AWSPCAIssuerSpec{
+			Region: "us-east-1",
+			Arn:    "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+		},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(iss).
+		WithStatusSubresource(iss).
+		Build()
+
+	controller := GenericIssuerReconciler{
+		Client:   fakeClient,
+		Log:      logrtesting.NewTestLogger(t),
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(10),
+	}
+
+	notBefore := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2034, 1, 1, 0, 0, 0, 0, time.UTC)
+	NewProvisioner = generateMockNewProvisioner(&fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{
+		Status:    acmpcatypes.CertificateAuthorityStatusActive,
+		Type:      acmpcatypes.CertificateAuthorityTypeSubordinate,
+		UsageMode: acmpcatypes.CertificateAuthorityUsageModeShortLivedCertificate,
+		Serial:    aws.String("1a:2b"),
+		NotBefore: &notBefore,
+		NotAfter:  &notAfter,
+		CertificateAuthorityConfiguration: &acmpcatypes.CertificateAuthorityConfiguration{
+			KeyAlgorithm:     acmpcatypes.KeyAlgorithmEcPrime256v1,
+			SigningAlgorithm: acmpcatypes.SigningAlgorithmSha256withecdsa,
+		},
+	}}, nil)
+
+	_, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "clusterissuer1"}}, iss)
+	require.NoError(t, err)
+
+	got := &issuerapi.AWSPCAClusterIssuer{}
+	require.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: "clusterissuer1"}, got))
+	assert.Equal(t, "ACTIVE", got.Status.CAStatus)
+	assert.Equal(t, "SUBORDINATE", got.Status.CAType)
+	assert.Equal(t, "SHORT_LIVED_CERTIFICATE", got.Status.UsageMode)
+	assert.Equal(t, "EC_prime256v1", got.Status.KeyAlgorithm)
+	assert.Equal(t, "SHA256WITHECDSA", got.Status.SigningAlgorithm)
+	assert.Equal(t, "1a:2b", got.Status.Serial)
+	require.NotNil(t, got.Status.NotBefore)
+	assert.True(t, notBefore.Equal(got.Status.NotBefore.Time))
+	require.NotNil(t, got.Status.NotAfter)
+	assert.True(t, notAfter.Equal(got.Status.NotAfter.Time))
+	assert.Equal(t, int64(3), got.Status.ObservedGeneration)
+}
+
+func TestIssuerReconcileResyncAndExpiry(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	type testCase struct {
+		resyncInterval         *metav1.Duration
+		notAfter               time.Time
+		expectedRequeueAfter   time.Duration
+		expectedExpiringStatus metav1.ConditionStatus
+		expectWarningEvent     bool
+	}
+
+	tests := map[string]testCase{
+		"default-resync-interval": {
+			notAfter:               now.Add(365 * 24 * time.Hour),
+			expectedRequeueAfter:   time.Hour,
+			expectedExpiringStatus: metav1.ConditionFalse,
+		},
+		"issuer-resync-interval": {
+			resyncInterval:         &metav1.Duration{Duration: 10 * time.Minute},
+			notAfter:               now.Add(365 * 24 * time.Hour),
+			expectedRequeueAfter:   10 * time.Minute,
+			expectedExpiringStatus: metav1.ConditionFalse,
+		},
+		"issuer-resync-disabled": {
+			resyncInterval:         &metav1.Duration{},
+			notAfter:               now.Add(365 * 24 * time.Hour),
+			expectedRequeueAfter:   0,
+			expectedExpiringStatus: metav1.ConditionFalse,
+		},
+		"ca-expiring-soon": {
+			notAfter:               now.Add(10 * 24 * time.Hour),
+			expectedRequeueAfter:   time.Hour,
+			expectedExpiringStatus: metav1.ConditionTrue,
+			expectWarningEvent:     true,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			iss := &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "issuer1",
+					Namespace: "ns1",
+				},
+				Spec: issuerapi.AWSPCAIssuerSpec{
+					Region:         "us-east-1",
+					Arn:            "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012",
+					ResyncInterval: tc.resyncInterval,
+				},
+			}
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(iss).
+				WithStatusSubresource(iss).
+				Build()
+
+			recorder := record.NewFakeRecorder(10)
+			controller := GenericIssuerReconciler{
+				Client:   fakeClient,
+				Log:      logrtesting.NewTestLogger(t),
+				Scheme:   scheme,
+				Recorder: recorder,
+
+				Clock:                    clocktesting.NewFakePassiveClock(now),
+				ResyncInterval:           time.Hour,
+				CAExpiryWarningThreshold: 30 * 24 * time.Hour,
+			}
+			NewProvisioner = generateMockNewProvisioner(&fakeProvisioner{ca: &acmpcatypes.CertificateAuthority{
+				Status:   acmpcatypes.CertificateAuthorityStatusActive,
+				NotAfter: &tc.notAfter,
+			}}, nil)
+
+			name := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+			result, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: name}, iss)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRequeueAfter, result.RequeueAfter)
+
+			condition := findCondition(iss.Status.Conditions, issuerapi.ConditionTypeCAExpiringSoon)
+			require.NotNil(t, condition)
+			assert.Equal(t, tc.expectedExpiringStatus, condition.Status)
+
+			warned := false
+			close(recorder.Events)
+			for event := range recorder.Events {
+				if strings.HasPrefix(event, "Warning "+issuerapi.ConditionTypeCAExpiringSoon) {
+					warned = true
+				}
+			}
+			assert.Equal(t, tc.expectWarningEvent, warned)
+		})
+	}
+}
+
+func findCondition(conditions []metav1.Condition, conditionType string) *metav1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateIssuer(t *testing.T) {
+	arn := "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012"
+
+	type testCase struct {
+		spec          issuerapi.AWSPCAIssuerSpec
+		expectedError bool
+	}
+
+	tests := map[string]testCase{
+		"valid": {
+			spec: issuerapi.AWSPCAIssuerSpec{Arn: arn},
+		},
+		"region-mismatch": {
+			spec:          issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "eu-west-1"},
+			expectedError: true,
+		},
+		"invalid-role": {
+			spec:          issuerapi.AWSPCAIssuerSpec{Arn: arn, Role: "role"},
+			expectedError: true,
+		},
+		"invalid-template": {
+			spec:          issuerapi.AWSPCAIssuerSpec{Arn: arn, TemplateArn: "template"},
+			expectedError: true,
+		},
+		"negative-max-duration": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:         arn,
+				MaxDuration: &metav1.Duration{Duration: -time.Hour},
+			},
+			expectedError: true,
+		},
+		"invalid-signing-algorithm": {
+			spec:          issuerapi.AWSPCAIssuerSpec{Arn: arn, SigningAlgorithm: "MD5WITHRSA"},
+			expectedError: true,
+		},
+		"invalid-issuance-policy": {
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				IssuancePolicy: &issuerapi.IssuancePolicy{AllowedDNSNames: []string{"("}},
+			},
+			expectedError: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateIssuer(types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &tc.spec)
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	assertErrorIs(t, errNoArnInSpec, validateIssuer(types.NamespacedName{Namespace: "ns1", Name: "issuer1"}, &issuerapi.AWSPCAIssuerSpec{}))
+}
+
+func TestIssuerReconcileSecretNamespaces(t *testing.T) {
+	type testCase struct {
+		name                         types.NamespacedName
+		spec                         issuerapi.AWSPCAIssuerSpec
+		clusterResourceNamespace     string
+		expectedReadyConditionStatus metav1.ConditionStatus
+		expectedReadyConditionReason string
+	}
+
+	secretRef := func(namespace string) issuerapi.AWSCredentialsSecretReference {
+		return issuerapi.AWSCredentialsSecretReference{
+			SecretReference: v1.SecretReference{Namespace: namespace, Name: "credentials"},
+		}
+	}
+	arn := "arn:aws:acm-pca:us-east-1:account:certificate-authority/12345678-1234-1234-1234-123456789012"
+	issuer := types.NamespacedName{Namespace: "ns1", Name: "issuer1"}
+	clusterIssuer := types.NamespacedName{Name: "clusterissuer1"}
+
+	tests := map[string]testCase{
+		"issuer-own-namespace": {
+			name:                         issuer,
+			spec:                         issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", SecretRef: secretRef("ns1")},
+			expectedReadyConditionStatus: metav1.ConditionTrue,
+			expectedReadyConditionReason: "Verified",
+		},
+		"issuer-other-namespace": {
+			name:                         issuer,
+			spec:                         issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", SecretRef: secretRef("ns2")},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Validation",
+		},
+		"cluster-issuer-unrestricted": {
+			name:                         clusterIssuer,
+			spec:                         issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", SecretRef: secretRef("ns2")},
+			expectedReadyConditionStatus: metav1.ConditionTrue,
+			expectedReadyConditionReason: "Verified",
+		},
+		"cluster-issuer-cluster-resource-namespace": {
+			name:                         clusterIssuer,
+			spec:                         issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", SecretRef: secretRef("ns1")},
+			clusterResourceNamespace:     "ns1",
+			expectedReadyConditionStatus: metav1.ConditionTrue,
+			expectedReadyConditionReason: "Verified",
+		},
+		"cluster-issuer-outside-cluster-resource-namespace": {
+			name:                         clusterIssuer,
+			spec:                         issuerapi.AWSPCAIssuerSpec{Arn: arn, Region: "us-east-1", SecretRef: secretRef("ns2")},
+			clusterResourceNamespace:     "ns1",
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Validation",
+		},
+		"issuer-credentials-other-namespace": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "other-namespace"},
+			},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Validation",
+		},
+		"issuer-cluster-credentials": {
+			name: issuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared", Kind: issuerapi.ClusterCredentialsKind},
+			},
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Validation",
+		},
+		"cluster-issuer-cluster-credentials-outside-cluster-resource-namespace": {
+			name: clusterIssuer,
+			spec: issuerapi.AWSPCAIssuerSpec{
+				Arn:            arn,
+				CredentialsRef: &issuerapi.CredentialsReference{Name: "shared"},
+			},
+			clusterResourceNamespace:     "ns1",
+			expectedReadyConditionStatus: metav1.ConditionFalse,
+			expectedReadyConditionReason: "Validation",
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var iss issuerapi.GenericIssuer = &issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.name.Name, Namespace: tc.name.Namespace},
+				Spec:       tc.spec,
+			}
+			if tc.name.Namespace == "" {
+				iss = &issuerapi.AWSPCAClusterIssuer{
+					ObjectMeta: metav1.ObjectMeta{Name: tc.name.Name},
+					Spec:       tc.spec,
+				}
+			}
+
+			var objects []client.Object
+			for _, namespace := range []string{"ns1", "ns2"} {
+				objects = append(objects, &v1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "credentials"},
+					Data: map[string][]byte{
+						"AWS_ACCESS_KEY_ID":     []byte("ZSBhd3MtcGNhLWlzc3Vlci1rZXktaWQ="),
+						"AWS_SECRET_ACCESS_KEY": []byte("ZSBhd3MtcGNhLWlzc3Vlci1zZWNyZXQta2V5"),
+					},
+				})
+			}
+			objects = append(objects,
+				&issuerapi.AWSPCACredentials{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "other-namespace"},
+					Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1", SecretRef: secretRef("ns2")},
+				},
+				&issuerapi.AWSPCAClusterCredentials{
+					ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+					Spec:       issuerapi.AWSPCACredentialsSpec{Region: "us-east-1", SecretRef: secretRef("ns2")},
+				},
+				iss,
+			)
+
+			fakeClient := fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(objects...).
+				WithStatusSubresource(iss).
+				Build()
+
+			controller := GenericIssuerReconciler{
+				Client:   fakeClient,
+				Log:      logrtesting.NewTestLogger(t),
+				Scheme:   scheme,
+				Recorder: record.NewFakeRecorder(10),
+			}
+			NewProvisioner = generateMockNewProvisioner(&fakeProvisioner{}, nil)
+			awspca.SetClusterResourceNamespace(tc.clusterResourceNamespace)
+			t.Cleanup(func() { awspca.SetClusterResourceNamespace("") })
+
+			_, err := controller.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tc.name}, iss)
+			if tc.expectedReadyConditionStatus == metav1.ConditionTrue {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+
+			assertIssuerHasReadyCondition(t, tc.expectedReadyConditionStatus, iss.GetStatus())
+			assert.Equal(t, tc.expectedReadyConditionReason, iss.GetStatus().Conditions[0].Reason, "unexpected condition reason")
+		})
+	}
+}
+
+func TestIssuersForSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	secretRef := func(namespace, name string) issuerapi.AWSCredentialsSecretReference {
+		return issuerapi.AWSCredentialsSecretReference{
+			SecretReference: v1.SecretReference{Namespace: namespace, Name: name},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&issuerapi.AWSPCAIssuer{}, secretRefIndexField, indexIssuerSecretRef).
+		WithIndex(&issuerapi.AWSPCAClusterIssuer{}, secretRefIndexField, indexIssuerSecretRef).
+		WithObjects(
+			&issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{SecretRef: secretRef("ns1", "credentials")},
+			},
+			&issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer2", Namespace: "ns1"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{SecretRef: secretRef("ns1", "other-credentials")},
+			},
+			&issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer3", Namespace: "ns2"},
+			},
+			&issuerapi.AWSPCAClusterIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "clusterissuer1"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{SecretRef: secretRef("ns1", "credentials")},
+			},
+		).
+		Build()
+
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"}}
+
+	issuers := (&AWSPCAIssuerReconciler{Client: fakeClient}).issuersForSecret(context.TODO(), secret)
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "issuer1"}},
+	}, issuers)
+
+	clusterIssuers := (&AWSPCAClusterIssuerReconciler{Client: fakeClient}).issuersForSecret(context.TODO(), secret)
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "clusterissuer1"}},
+	}, clusterIssuers)
+
+	unused := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unused", Namespace: "ns1"}}
+	assert.Empty(t, (&AWSPCAIssuerReconciler{Client: fakeClient}).issuersForSecret(context.TODO(), unused))
+}
+
+func TestIssuersForCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+	require.NoError(t, v1.AddToScheme(scheme))
+
+	credentialsRef := func(name, kind string) *issuerapi.CredentialsReference {
+		return &issuerapi.CredentialsReference{Name: name, Kind: kind}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithIndex(&issuerapi.AWSPCAIssuer{}, secretRefIndexField, indexIssuerSecretRef).
+		WithIndex(&issuerapi.AWSPCAClusterIssuer{}, secretRefIndexField, indexIssuerSecretRef).
+		WithIndex(&issuerapi.AWSPCAIssuer{}, credentialsRefIndexField, indexIssuerCredentialsRef).
+		WithIndex(&issuerapi.AWSPCAClusterIssuer{}, credentialsRefIndexField, indexIssuerCredentialsRef).
+		WithObjects(
+			&issuerapi.AWSPCACredentials{
+				ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns1"},
+				Spec: issuerapi.AWSPCACredentialsSpec{
+					SecretRef: issuerapi.AWSCredentialsSecretReference{
+						SecretReference: v1.SecretReference{Namespace: "ns1", Name: "credentials"},
+					},
+				},
+			},
+			&issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{CredentialsRef: credentialsRef("shared", "")},
+			},
+			&issuerapi.AWSPCAIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "issuer3", Namespace: "ns2"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{CredentialsRef: credentialsRef("shared", "")},
+			},
+			&issuerapi.AWSPCAClusterIssuer{
+				ObjectMeta: metav1.ObjectMeta{Name: "clusterissuer1"},
+				Spec:       issuerapi.AWSPCAIssuerSpec{CredentialsRef: credentialsRef("shared", "")},
+			},
+		).
+		Build()
+
+	credentials := &issuerapi.AWSPCACredentials{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "ns1"}}
+	clusterCredentials := &issuerapi.AWSPCAClusterCredentials{ObjectMeta: metav1.ObjectMeta{Name: "shared"}}
+
+	issuerReconciler := &AWSPCAIssuerReconciler{Client: fakeClient}
+	clusterIssuerReconciler := &AWSPCAClusterIssuerReconciler{Client: fakeClient}
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "issuer1"}},
+	}, issuerReconciler.issuersForCredentials(issuerapi.CredentialsKind)(context.TODO(), credentials))
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "clusterissuer1"}},
+	}, clusterIssuerReconciler.issuersForCredentials(issuerapi.ClusterCredentialsKind)(context.TODO(), clusterCredentials))
+
+	// Rotating the Secret of shared credentials re-reconciles the issuers using them
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "ns1"}}
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "ns1", Name: "issuer1"}},
+	}, issuerReconciler.issuersForSecret(context.TODO(), secret))
+}
+
 func assertErrorIs(t *testing.T, expectedError, actualError error) {
 	if !assert.Error(t, actualError) {
 		return
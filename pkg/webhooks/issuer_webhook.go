@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	api "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	awspca "github.com/cert-manager/aws-privateca-issuer/pkg/aws"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/mutate-awspca-cert-manager-io-v1beta1-awspcaissuer,mutating=true,failurePolicy=fail,sideEffects=None,groups=awspca.cert-manager.io,resources=awspcaissuers,verbs=create;update,versions=v1beta1,name=mawspcaissuer.awspca.cert-manager.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate-awspca-cert-manager-io-v1beta1-awspcaclusterissuer,mutating=true,failurePolicy=fail,sideEffects=None,groups=awspca.cert-manager.io,resources=awspcaclusterissuers,verbs=create;update,versions=v1beta1,name=mawspcaclusterissuer.awspca.cert-manager.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-awspca-cert-manager-io-v1beta1-awspcaissuer,mutating=false,failurePolicy=fail,sideEffects=None,groups=awspca.cert-manager.io,resources=awspcaissuers,verbs=create;update,versions=v1beta1,name=vawspcaissuer.awspca.cert-manager.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-awspca-cert-manager-io-v1beta1-awspcaclusterissuer,mutating=false,failurePolicy=fail,sideEffects=None,groups=awspca.cert-manager.io,resources=awspcaclusterissuers,verbs=create;update,versions=v1beta1,name=vawspcaclusterissuer.awspca.cert-manager.io,admissionReviewVersions=v1
+
+// SetupWithManager registers the defaulting and validating webhooks of
+// AWSPCAIssuer and AWSPCAClusterIssuer
+func SetupWithManager(mgr ctrl.Manager) error {
+	for _, issuer := range []runtime.Object{&api.AWSPCAIssuer{}, &api.AWSPCAClusterIssuer{}} {
+		err := ctrl.NewWebhookManagedBy(mgr).
+			For(issuer).
+			WithDefaulter(&IssuerDefaulter{}).
+			WithValidator(&IssuerValidator{}).
+			Complete()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IssuerDefaulter defaults AWSPCAIssuers and AWSPCAClusterIssuers
+type IssuerDefaulter struct{}
+
+var _ admission.CustomDefaulter = &IssuerDefaulter{}
+
+// Default fills in the keys of the credentials Secret and, for AWSPCAIssuers,
+// the namespace of referenced Secrets
+func (d *IssuerDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	issuer, ok := obj.(api.GenericIssuer)
+	if !ok {
+		return fmt.Errorf("expected an issuer but got %T", obj)
+	}
+	spec := issuer.GetSpec()
+
+	if ref := &spec.SecretRef; ref.Name != "" {
+		if ref.Namespace == "" {
+			ref.Namespace = issuer.GetNamespace()
+		}
+		if ref.AccessKeyIDSelector.Key == "" {
+			ref.AccessKeyIDSelector.Key = awspca.DefaultAccessKeyIDKey
+		}
+		if ref.SecretAccessKeySelector.Key == "" {
+			ref.SecretAccessKeySelector.Key = awspca.DefaultSecretAccessKeyKey
+		}
+	}
+	if ra := spec.RolesAnywhere; ra != nil && ra.CertificateSecretRef.Namespace == "" {
+		ra.CertificateSecretRef.Namespace = issuer.GetNamespace()
+	}
+	return nil
+}
+
+// IssuerValidator validates AWSPCAIssuers and AWSPCAClusterIssuers
+type IssuerValidator struct{}
+
+var _ admission.CustomValidator = &IssuerValidator{}
+
+// ValidateCreate validates a new issuer
+func (v *IssuerValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, validate(obj)
+}
+
+// ValidateUpdate validates an updated issuer
+func (v *IssuerValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, validate(newObj)
+}
+
+// ValidateDelete allows issuers to be deleted
+func (v *IssuerValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validate(obj runtime.Object) error {
+	issuer, ok := obj.(api.GenericIssuer)
+	if !ok {
+		return fmt.Errorf("expected an issuer but got %T", obj)
+	}
+
+	name := types.NamespacedName{Namespace: issuer.GetNamespace(), Name: issuer.GetName()}
+	errs := awspca.ValidateIssuerSpec(name, issuer.GetSpec(), field.NewPath("spec"))
+	if len(errs) == 0 {
+		return nil
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	return apierrors.NewInvalid(gvk.GroupKind(), issuer.GetName(), errs)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package webhooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	issuerapi "github.com/cert-manager/aws-privateca-issuer/pkg/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const caArn = "arn:aws:acm-pca:us-east-1:123456789012:certificate-authority/12345678-1234-1234-1234-123456789012"
+
+func TestIssuerDefaulter(t *testing.T) {
+	issuer := &issuerapi.AWSPCAIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "ns1"},
+		Spec: issuerapi.AWSPCAIssuerSpec{
+			Arn: caArn,
+			SecretRef: issuerapi.AWSCredentialsSecretReference{
+				SecretReference:     v1.SecretReference{Name: "credentials"},
+				AccessKeyIDSelector: v1.SecretKeySelector{Key: "id"},
+			},
+		},
+	}
+
+	require.NoError(t, (&IssuerDefaulter{}).Default(context.TODO(), issuer))
+
+	ref := issuer.Spec.SecretRef
+	assert.Equal(t, "ns1", ref.Namespace)
+	assert.Equal(t, "id", ref.AccessKeyIDSelector.Key)
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY", ref.SecretAccessKeySelector.Key)
+	// Selected session keys are required to exist, so they are not defaulted
+	assert.Empty(t, ref.SessionTokenSelector.Key)
+	assert.Empty(t, ref.ExpirationSelector.Key)
+
+	clusterIssuer := &issuerapi.AWSPCAClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusterissuer1"},
+		Spec:       issuerapi.AWSPCAIssuerSpec{Arn: caArn},
+	}
+	require.NoError(t, (&IssuerDefaulter{}).Default(context.TODO(), clusterIssuer))
+	assert.Equal(t, issuerapi.AWSCredentialsSecretReference{}, clusterIssuer.Spec.SecretRef)
+}
+
+// TestWebhooks serves the webhooks to a local API server. It requires the
+// binaries installed by setup-envtest, and is skipped when KUBEBUILDER_ASSETS
+// is not set.
+func TestWebhooks(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set")
+	}
+
+	env := &envtest.Environment{
+		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "config", "webhook")},
+		},
+	}
+	cfg, err := env.Start()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = env.Stop() })
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, issuerapi.AddToScheme(scheme))
+
+	options := env.WebhookInstallOptions
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    options.LocalServingHost,
+			Port:    options.LocalServingPort,
+			CertDir: options.LocalServingCertDir,
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetupWithManager(mgr))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = mgr.Start(ctx)
+	}()
+
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	require.NoError(t, err)
+
+	issuer := &issuerapi.AWSPCAIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "issuer1", Namespace: "default"},
+		Spec: issuerapi.AWSPCAIssuerSpec{
+			Arn: caArn,
+			SecretRef: issuerapi.AWSCredentialsSecretReference{
+				SecretReference: v1.SecretReference{Name: "credentials"},
+			},
+		},
+	}
+	// The webhook server may take a moment to start serving
+	require.Eventually(t, func() bool {
+		return c.Create(ctx, issuer) == nil
+	}, 10*time.Second, 100*time.Millisecond)
+	assert.Equal(t, "default", issuer.Spec.SecretRef.Namespace)
+	assert.Equal(t, "AWS_ACCESS_KEY_ID", issuer.Spec.SecretRef.AccessKeyIDSelector.Key)
+
+	clusterIssuer := &issuerapi.AWSPCAClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusterissuer1"},
+		Spec:       issuerapi.AWSPCAIssuerSpec{Arn: caArn, Region: "eu-west-1"},
+	}
+	err = c.Create(ctx, clusterIssuer)
+	assert.True(t, apierrors.IsInvalid(err), "expected the region mismatch to be rejected, got %v", err)
+}